@@ -1,21 +1,33 @@
 //spellchecker:words goprogram
 package goprogram
 
-//spellchecker:words slices
-import "slices"
+//spellchecker:words slices strconv strings github goprogram exit
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.tkw01536.de/goprogram/exit"
+)
 
 // Alias represents an alias for a command.
 //
 // Expansion of an alias takes place at runtime.
 // Aliases must not contain global flags; execution of the them will fail at runtime.
 //
-// Aliases are not expanded recursively, meaning one alias may not refer to itself or another.
+// Aliases are expanded recursively, meaning one alias may refer to another alias.
+// An alias that refers to its own name refers to the command with the same name.
 // An alias always takes precedence over a command with the same name.
+//
+// Expansion fails at runtime when aliases refer to each other in a cycle,
+// or when more than MaxAliasDepth aliases would have to be expanded.
 type Alias struct {
 	// Name is the name of this alias
 	Name string
 
-	// Command to invoke along with arguments
+	// Command to invoke along with arguments.
+	// Command may refer to a command within a group, see Group.
 	Command string
 	Args    []string
 
@@ -23,7 +35,8 @@ type Alias struct {
 	Description string
 }
 
-// args are additional arguments to pass to the command.
+// Invoke returns the command and arguments to invoke when this alias is called with the given arguments.
+// It does not expand any further aliases.
 func (a Alias) Invoke(args []string) (command string, arguments []string) {
 	// setup command
 	command = a.Command
@@ -36,8 +49,9 @@ func (a Alias) Invoke(args []string) (command string, arguments []string) {
 }
 
 // Expansion returns a slice representing the expansion of this alias.
+// When the alias refers to a command inside a group, the name of the command is split into words.
 func (a Alias) Expansion() []string {
-	return append([]string{a.Command}, a.Args...)
+	return append(strings.Fields(a.Command), a.Args...)
 }
 
 // RegisterAlias registers a new alias.
@@ -67,3 +81,60 @@ func (p Program[E, P, F, R]) Aliases() []string {
 	slices.Sort(aliases)
 	return aliases
 }
+
+// MaxAliasDepth is the maximum number of aliases expanded for a single invocation.
+const MaxAliasDepth = 16
+
+var (
+	errAliasCycle   = exit.NewErrorWithCode("alias expansion failed: aliases form a cycle", exit.ExitUnknownCommand)
+	errAliasTooDeep = exit.NewErrorWithCode("alias expansion failed: too many nested aliases", exit.ExitUnknownCommand)
+)
+
+// expandAliases returns the chain of aliases to expand when invoking name.
+// When name does not refer to an alias, returns an empty chain.
+func (p Program[E, P, F, R]) expandAliases(name string) ([]Alias, error) {
+	alias, ok := p.aliases[name]
+	if !ok {
+		return nil, nil
+	}
+	return p.aliasChain(alias)
+}
+
+// aliasChain returns the chain of aliases to expand when invoking alias.
+// The chain starts with alias itself, followed by any aliases it refers to.
+//
+// When the aliases form a cycle, or the chain is longer than MaxAliasDepth, an error is returned.
+func (p Program[E, P, F, R]) aliasChain(alias Alias) ([]Alias, error) {
+	chain := []Alias{alias}
+	for {
+		// an alias referring to its own name refers to the command
+		if alias.Command == alias.Name {
+			return chain, nil
+		}
+
+		next, ok := p.aliases[alias.Command]
+		if !ok {
+			return chain, nil
+		}
+
+		if slices.ContainsFunc(chain, func(a Alias) bool { return a.Name == next.Name }) {
+			return nil, fmt.Errorf("%w: %s", errAliasCycle, fmtAliasChain(append(chain, next)))
+		}
+
+		chain = append(chain, next)
+		if len(chain) > MaxAliasDepth {
+			return nil, fmt.Errorf("%w: %s", errAliasTooDeep, fmtAliasChain(chain))
+		}
+
+		alias = next
+	}
+}
+
+// fmtAliasChain formats a chain of aliases for use in an error message.
+func fmtAliasChain(chain []Alias) string {
+	names := make([]string, len(chain))
+	for i, alias := range chain {
+		names[i] = strconv.Quote(alias.Name)
+	}
+	return strings.Join(names, " -> ")
+}
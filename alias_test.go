@@ -1,11 +1,14 @@
 //spellchecker:words goprogram
 package goprogram //nolint:testpackage
 
-//spellchecker:words reflect testing github pkglib stream
+//spellchecker:words bytes reflect testing github goprogram exit pkglib stream
 import (
+	"bytes"
+	"fmt"
 	"reflect"
 	"testing"
 
+	"go.tkw01536.de/goprogram/exit"
 	"go.tkw01536.de/pkglib/stream"
 )
 
@@ -47,3 +50,120 @@ func TestProgram_Alias(t *testing.T) {
 		t.Errorf("Program.Aliases() = %v, want = %v", got, want)
 	}
 }
+
+func TestProgram_aliasChain(t *testing.T) {
+	t.Parallel()
+
+	var p iProgram
+	p.RegisterAlias(Alias{Name: "a", Command: "b", Args: []string{"1"}})
+	p.RegisterAlias(Alias{Name: "b", Command: "c", Args: []string{"2"}})
+	p.RegisterAlias(Alias{Name: "c", Command: "c", Args: []string{"3"}})
+
+	p.RegisterAlias(Alias{Name: "x", Command: "y"})
+	p.RegisterAlias(Alias{Name: "y", Command: "z"})
+	p.RegisterAlias(Alias{Name: "z", Command: "x"})
+
+	for i := range MaxAliasDepth + 1 {
+		p.RegisterAlias(Alias{Name: fmt.Sprintf("deep%d", i), Command: fmt.Sprintf("deep%d", i+1)})
+	}
+
+	tests := []struct {
+		name    string
+		want    []string
+		wantErr string
+	}{
+		{"not an alias", nil, ""},
+		{"c", []string{"c"}, ""},
+		{"b", []string{"b", "c"}, ""},
+		{"a", []string{"a", "b", "c"}, ""},
+		{"x", nil, `alias expansion failed: aliases form a cycle: "x" -> "y" -> "z" -> "x"`},
+		{"deep0", nil, `alias expansion failed: too many nested aliases: "deep0" -> "deep1" -> "deep2" -> "deep3" -> "deep4" -> "deep5" -> "deep6" -> "deep7" -> "deep8" -> "deep9" -> "deep10" -> "deep11" -> "deep12" -> "deep13" -> "deep14" -> "deep15" -> "deep16"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			chain, err := p.expandAliases(tt.name)
+
+			var gotErr string
+			if err != nil {
+				gotErr = err.Error()
+			}
+			if gotErr != tt.wantErr {
+				t.Errorf("Program.expandAliases() error = %q, want %q", gotErr, tt.wantErr)
+			}
+
+			var got []string
+			for _, alias := range chain {
+				got = append(got, alias.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Program.expandAliases() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgram_Main_recursiveAlias(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+
+		wantStdout string
+		wantStderr string
+		wantCode   uint8
+	}{
+		{
+			name:       "alias to alias",
+			args:       []string{"hi", "again"},
+			wantStdout: "[hello world again]\n",
+		},
+		{
+			name:       "alias to alias to alias",
+			args:       []string{"hey"},
+			wantStdout: "[hello world you]\n",
+		},
+		{
+			name:       "cyclic alias",
+			args:       []string{"ping"},
+			wantStderr: "alias expansion failed: aliases form a cycle: \"ping\" -> \"pong\" -> \"ping\"\n",
+			wantCode:   2,
+		},
+		{
+			name:       "alias chain help",
+			args:       []string{"hey", "--help"},
+			wantStdout: "Usage: exe [--help|-h] [--version|-v] [--timeout duration] [--color when] [--global-one|-a] [--global-two|-b] [--] hey [--] [ARG ...]\n\nalias for `exe hi you`, expanding to `exe hello world you`, expanding to `exe echo hello world you`. see `exe echo --help` for detailed help page about echo\n\nGlobal Arguments:\n\n   -h, --help\n      print a help message and exit\n\n   -v, --version\n      print a version message and exit\n\n   --timeout duration\n      maximum time the command may run\n\n   --color when\n      when to use colors in output (choices: auto, always, never; default auto)\n\n   -a, --global-one\n      \n\n   -b, --global-two\n      \n\nCommand Arguments:\n\n   [ARG ...]\n      arguments to pass after `exe echo hello world you`\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdoutBuffer bytes.Buffer
+			var stderrBuffer bytes.Buffer
+			stream := stream.NewIOStream(&stdoutBuffer, &stderrBuffer, nil)
+
+			program := makeProgram()
+			program.Register(makeEchoCommand("echo"))
+			program.RegisterAlias(Alias{Name: "hello", Command: "echo", Args: []string{"hello"}})
+			program.RegisterAlias(Alias{Name: "hi", Command: "hello", Args: []string{"world"}})
+			program.RegisterAlias(Alias{Name: "hey", Command: "hi", Args: []string{"you"}})
+			program.RegisterAlias(Alias{Name: "ping", Command: "pong"})
+			program.RegisterAlias(Alias{Name: "pong", Command: "ping"})
+
+			code, _ := exit.CodeFromError(program.Main(stream, "", tt.args))
+
+			if gotCode := uint8(code); gotCode != tt.wantCode {
+				t.Errorf("Program.Main() code = %v, wantCode %v", gotCode, tt.wantCode)
+			}
+			if gotStdout := stdoutBuffer.String(); gotStdout != tt.wantStdout {
+				t.Errorf("Program.Main() stdout = %q, wantStdout %q", gotStdout, tt.wantStdout)
+			}
+			if gotStderr := stderrBuffer.String(); gotStderr != tt.wantStderr {
+				t.Errorf("Program.Main() stderr = %q, wantStderr %q", gotStderr, tt.wantStderr)
+			}
+		})
+	}
+}
@@ -1,11 +1,14 @@
 //spellchecker:words goprogram
 package goprogram
 
-//spellchecker:words reflect slices github goprogram exit meta parser pkglib reflectx
+//spellchecker:words reflect slices strconv strings time github goprogram exit meta parser pkglib reflectx
 import (
 	"fmt"
 	"reflect"
 	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"go.tkw01536.de/goprogram/exit"
 	"go.tkw01536.de/goprogram/meta"
@@ -55,12 +58,61 @@ type AfterParseCommand[E any, P any, F any, R Requirement[F]] interface {
 // Description describes a command, and specifies any potential requirements.
 type Description[F any, R Requirement[F]] struct {
 	// Command and Description the name and human-readable description of this command.
-	// Command must not be taken by any other command registered with the corresponding program.
+	// Command must not be taken by any other command or group registered with the corresponding program.
+	//
+	// To place a command inside a group, Command should hold the full name of the group, followed by a space and the name of the command.
+	// See Group for details.
 	Command     string
 	Description string
 
+	// Summary is a one-line summary of the command, shown next to its name when commands are listed.
+	// When empty, the first sentence of Description is used instead.
+	Summary string
+
+	// Examples holds example invocations of the command, shown on its usage page.
+	// Use Program.CheckExamples to verify that they succeed and produce the expected output.
+	Examples []meta.Example
+
+	// Category optionally names the section the command is listed in on the main usage page, see Program.Categories.
+	// It is only used for top-level commands.
+	Category string
+
 	// Requirements on the environment to be able to run the command
 	Requirements R
+
+	// Timeout is the default time limit for running the command, or zero for no limit.
+	// It can be overridden using the universal "--timeout" flag.
+	//
+	// The time limit is applied to the context of the command as a deadline.
+	// When the command returns an error after the deadline has passed, the error uses exit.ExitTimeout.
+	Timeout time.Duration
+
+	// Hidden hides the command from usage pages, documentation, completion and suggestions.
+	// A hidden command can still be invoked using its full name.
+	Hidden bool
+
+	// Deprecated marks the command as deprecated, unless it is nil.
+	// Invoking a deprecated command prints a warning to standard error before it is run,
+	// and the usage page of a deprecated command describes the deprecation.
+	Deprecated *Deprecation
+}
+
+// Deprecation describes the deprecation of a command, see Description.
+type Deprecation struct {
+	Replacement string // name of the command to use instead, if any
+	Removal     string // version in which the command will be removed, if known
+}
+
+// Notice returns a human-readable notice that the command with the given name is deprecated.
+func (d Deprecation) Notice(name string) string {
+	notice := fmt.Sprintf("command %q is deprecated", name)
+	if d.Removal != "" {
+		notice += " and will be removed in version " + d.Removal
+	}
+	if d.Replacement != "" {
+		notice += fmt.Sprintf("; use %q instead", d.Replacement)
+	}
+	return notice
 }
 
 // Requirement describes a requirement on a type of Flags F.
@@ -87,6 +139,7 @@ func (EmptyRequirement[F]) Validate(Arguments[F]) error { return nil }
 // Register registers a command c with this program.
 //
 // It expects that the command does not have a name that is already taken.
+// If the command belongs to a group, the group must have been registered beforehand.
 func (p *Program[E, P, F, R]) Register(c Command[E, P, F, R]) {
 	if p.commands == nil {
 		p.commands = make(map[string]Command[E, P, F, R])
@@ -97,11 +150,18 @@ func (p *Program[E, P, F, R]) Register(c Command[E, P, F, R]) {
 	if _, ok := p.commands[Name]; ok {
 		panic("Register(): Command already registered")
 	}
+	if _, ok := p.groups[Name]; ok {
+		panic("Register(): Group with the same name already registered")
+	}
+	if !p.hasGroup(parentName(Name)) {
+		panic("Register(): Group not registered")
+	}
 
 	p.commands[Name] = c
 }
 
 // Commands returns a list of known commands.
+// Commands inside groups are returned using their full name.
 func (p Program[E, P, F, R]) Commands() []string {
 	commands := make([]string, 0, len(p.commands))
 	for cmd := range p.commands {
@@ -111,16 +171,81 @@ func (p Program[E, P, F, R]) Commands() []string {
 	return commands
 }
 
-// Command returns the command with the provided name and if it exists.
+// listedCommands is like Commands, but omits hidden commands.
+func (p Program[E, P, F, R]) listedCommands() []string {
+	commands := p.Commands()
+	return slices.DeleteFunc(commands, func(name string) bool {
+		return p.commands[name].Description().Hidden
+	})
+}
+
+// Command returns the command with the provided (full) name and if it exists.
+//
+// If p.AbbreviateCommands is set, each word of name may be abbreviated.
+// An ambiguous abbreviation results in no command being returned.
 func (p Program[E, P, F, R]) Command(name string) (Command[E, P, F, R], bool) {
-	// NOTE: This function is not tested because it is so trivial
 	cmd, ok := p.commands[name]
+	if !ok && p.AbbreviateCommands {
+		var group string
+		for word := range strings.SplitSeq(name, " ") {
+			child, err := p.expandAbbreviation(group, word, false)
+			if err != nil {
+				return nil, false
+			}
+			group = joinName(group, child)
+		}
+		cmd, ok = p.commands[group]
+	}
 	if ok {
 		cmd, _ = reflectx.CopyInterface(cmd)
 	}
 	return cmd, ok
 }
 
+// expandAbbreviation expands an abbreviated name of a command or group inside the given group.
+// When aliases is true, the names of aliases are also taken into account.
+//
+// If p.AbbreviateCommands is not set, name is an exact name, or name is not the prefix of any name, it is returned unchanged.
+// If name is an ambiguous abbreviation, returns an error.
+func (p Program[E, P, F, R]) expandAbbreviation(group, name string, aliases bool) (string, error) {
+	if !p.AbbreviateCommands || name == "" || strings.Contains(name, " ") {
+		return name, nil
+	}
+
+	// exact names (including those of hidden commands) are never abbreviations
+	if _, ok := p.commands[joinName(group, name)]; ok {
+		return name, nil
+	}
+
+	candidates := p.children(group)
+	if aliases {
+		candidates = append(candidates, p.Aliases()...)
+	}
+	if slices.Contains(candidates, name) {
+		return name, nil
+	}
+
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, name) {
+			matches = append(matches, candidate)
+		}
+	}
+	matches = slices.Compact(slices.Sorted(slices.Values(matches)))
+
+	switch len(matches) {
+	case 0:
+		return name, nil
+	case 1:
+		return matches[0], nil
+	}
+
+	for i, match := range matches {
+		matches[i] = strconv.Quote(joinName(group, match))
+	}
+	return "", fmt.Errorf("%w %q: could be %s", errProgramAmbiguous, joinName(group, name), fmtSuggestions(matches))
+}
+
 // FmtCommands returns a human readable string describing the commands.
 // See also Commands.
 func (p Program[E, P, F, R]) FmtCommands() string {
@@ -156,17 +281,28 @@ func ValidateAllowedFlags[F any](r Requirement[F], args Arguments[F]) error {
 
 var universalOpts = parser.AllFlags[Universals]()
 
-// globalOptions returns a list of global options for a command with the provided flag type.
-func globalOptions[F any]() (flags []meta.Flag) {
+// universalFlags returns the list of universal flags of this program.
+func (p Program[E, P, F, R]) universalFlags() (flags []meta.Flag) {
 	flags = append(flags, universalOpts...)
-	flags = append(flags, parser.AllFlags[F]()...)
+	if p.ConfigFile != "" {
+		flags = append(flags, configOpts...)
+	}
 	return
 }
 
-// globalFlagsFor returns a list of global options for a command with the provided flag type.
-func globalFlagsFor[F any](r Requirement[F]) (flags []meta.Flag) {
+// globalOptions returns a list of global options of this program.
+// Flags are bound to environment variables as configured by p.AutoEnv.
+func (p Program[E, P, F, R]) globalOptions() (flags []meta.Flag) {
+	flags = append(flags, p.universalFlags()...)
+	flags = append(flags, parser.AllFlagsWithEnv[F](p.envPrefix())...)
+	return
+}
+
+// globalFlagsFor returns a list of global options of this program allowed by the provided requirement.
+// Flags are bound to environment variables as configured by p.AutoEnv.
+func (p Program[E, P, F, R]) globalFlagsFor(r R) (flags []meta.Flag) {
 	// filter options to be those that are allowed
-	gFlags := parser.AllFlags[F]()
+	gFlags := parser.AllFlagsWithEnv[F](p.envPrefix())
 	n := 0
 	for _, flag := range gFlags {
 		if !r.AllowsFlag(flag) {
@@ -178,7 +314,7 @@ func globalFlagsFor[F any](r Requirement[F]) (flags []meta.Flag) {
 	gFlags = gFlags[:n]
 
 	// concat universal flags and normal flags
-	flags = append(flags, universalOpts...)
+	flags = append(flags, p.universalFlags()...)
 	flags = append(flags, gFlags...)
 	return
 }
@@ -1,11 +1,13 @@
 //spellchecker:words goprogram
 package goprogram //nolint:testpackage
 
-//spellchecker:words reflect testing github pkglib stream
+//spellchecker:words bytes reflect testing github goprogram exit pkglib stream
 import (
+	"bytes"
 	"reflect"
 	"testing"
 
+	"go.tkw01536.de/goprogram/exit"
 	"go.tkw01536.de/pkglib/stream"
 )
 
@@ -56,3 +58,259 @@ func TestProgram_FmtCommands(t *testing.T) {
 		t.Errorf("Program.FmtCommands() = %v, want = %v", got, want)
 	}
 }
+
+func TestProgram_Command_abbreviations(t *testing.T) {
+	t.Parallel()
+
+	p := makeProgram()
+	p.AbbreviateCommands = true
+	p.RegisterGroup(Group{Name: "repo"})
+	p.Register(makeEchoCommand("status"))
+	p.Register(makeEchoCommand("stash"))
+	p.Register(makeEchoCommand("fetch"))
+	p.Register(makeEchoCommand("repo list"))
+
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"fetch", "fetch", true},
+		{"f", "fetch", true},
+		{"stat", "status", true},
+		{"st", "", false},
+		{"r l", "repo list", true},
+		{"unknown", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd, ok := p.Command(tt.name)
+			if ok != tt.ok {
+				t.Fatalf("Program.Command() ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if got := cmd.Description().Command; got != tt.want {
+				t.Errorf("Program.Command() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgram_Main_abbreviations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		abbreviate bool
+		args       []string
+
+		wantStdout string
+		wantStderr string
+		wantCode   uint8
+	}{
+		{
+			name:       "exact command",
+			abbreviate: true,
+			args:       []string{"status", "hello"},
+			wantStdout: "[status hello]\n",
+		},
+		{
+			name:       "unique abbreviation",
+			abbreviate: true,
+			args:       []string{"stat", "hello"},
+			wantStdout: "[status hello]\n",
+		},
+		{
+			name:       "abbreviation of alias",
+			abbreviate: true,
+			args:       []string{"sh", "hello"},
+			wantStdout: "[show hello]\n",
+		},
+		{
+			name:       "abbreviation inside group",
+			abbreviate: true,
+			args:       []string{"r", "l", "hello"},
+			wantStdout: "[hello]\n",
+		},
+		{
+			name:       "exact match takes priority",
+			abbreviate: true,
+			args:       []string{"s", "hello"},
+			wantStdout: "[hello]\n",
+		},
+		{
+			name:       "keyword takes priority",
+			abbreviate: true,
+			args:       []string{"st", "hello"},
+			wantStdout: "[keyword hello]\n",
+		},
+		{
+			name:       "ambiguous abbreviation",
+			abbreviate: true,
+			args:       []string{"sta", "hello"},
+			wantStderr: "ambiguous command \"sta\": could be \"stash\" or \"status\"\n",
+			wantCode:   2,
+		},
+		{
+			name:       "abbreviations disabled",
+			abbreviate: false,
+			args:       []string{"stat", "hello"},
+			wantStderr: "unknown command: must be one of \"repo\", \"s\", \"stash\", \"status\"\n",
+			wantCode:   2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdoutBuffer bytes.Buffer
+			var stderrBuffer bytes.Buffer
+			stream := stream.NewIOStream(&stdoutBuffer, &stderrBuffer, nil)
+
+			program := makeProgram()
+			program.AbbreviateCommands = tt.abbreviate
+			program.RegisterGroup(Group{Name: "repo"})
+			program.Register(makeEchoCommand("s"))
+			program.Register(makeEchoCommand("status"))
+			program.Register(makeEchoCommand("stash"))
+			program.Register(makeEchoCommand("repo list"))
+			program.RegisterAlias(Alias{Name: "status", Command: "status", Args: []string{"status"}})
+			program.RegisterAlias(Alias{Name: "stash", Command: "stash", Args: []string{"stash"}})
+			program.RegisterAlias(Alias{Name: "show", Command: "s", Args: []string{"show"}})
+			program.RegisterAlias(Alias{Name: "list", Command: "repo list", Args: []string{"list"}})
+			program.RegisterKeyword("st", func(args *iArguments, pos *[]string) error {
+				args.Command = "s"
+				*pos = append([]string{"keyword"}, *pos...)
+				return nil
+			})
+
+			code, _ := exit.CodeFromError(program.Main(stream, "", tt.args))
+
+			if gotCode := uint8(code); gotCode != tt.wantCode {
+				t.Errorf("Program.Main() code = %v, wantCode %v", gotCode, tt.wantCode)
+			}
+			if gotStdout := stdoutBuffer.String(); gotStdout != tt.wantStdout {
+				t.Errorf("Program.Main() stdout = %q, wantStdout %q", gotStdout, tt.wantStdout)
+			}
+			if gotStderr := stderrBuffer.String(); gotStderr != tt.wantStderr {
+				t.Errorf("Program.Main() stderr = %q, wantStderr %q", gotStderr, tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestProgram_Main_hiddenAndDeprecated(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		abbreviate bool
+		args       []string
+
+		wantStdout string
+		wantStderr string
+		wantCode   uint8
+	}{
+		{
+			name:       "hidden command is runnable",
+			args:       []string{"secret", "hello"},
+			wantStdout: "[hello]\n",
+		},
+		{
+			name:       "hidden command does not make abbreviation ambiguous",
+			abbreviate: true,
+			args:       []string{"sec", "hello"},
+			wantStdout: "[hello]\n",
+		},
+		{
+			name:       "hidden command is runnable with abbreviations",
+			abbreviate: true,
+			args:       []string{"secret", "hello"},
+			wantStdout: "[hello]\n",
+		},
+		{
+			name:       "hidden command is not suggested",
+			args:       []string{"secre"},
+			wantStderr: "unknown command: must be one of \"new\", \"old\", \"second\"\n",
+			wantCode:   2,
+		},
+		{
+			name:       "hidden command is not completed",
+			args:       []string{CompleteCommand, "s"},
+			wantStdout: "second\n",
+		},
+		{
+			name:       "hidden command is not listed",
+			args:       []string{"--help"},
+			wantStdout: "Usage: exe [--help|-h] [--version|-v] [--timeout duration] [--color when] [--global-one|-a] [--global-two|-b] [--] COMMAND [ARGS...]\n\nsomething something dark side\n\n   -h, --help\n      print a help message and exit\n\n   -v, --version\n      print a version message and exit\n\n   --timeout duration\n      maximum time the command may run\n\n   --color when\n      when to use colors in output (choices: auto, always, never; default auto)\n\n   -a, --global-one\n      \n\n   -b, --global-two\n      \n\n   COMMAND [ARGS...]\n      Command to call. One of \"new\", \"old\", \"second\". See individual commands for more help.\n",
+		},
+		{
+			name:       "deprecated command warns",
+			args:       []string{"old", "hello"},
+			wantStdout: "[hello]\n",
+			wantStderr: "warning: command \"old\" is deprecated and will be removed in version 43.0.0; use \"new\" instead\n",
+		},
+		{
+			name:       "deprecated command help",
+			args:       []string{"old", "--help"},
+			wantStdout: "Usage: exe [--help|-h] [--version|-v] [--timeout duration] [--color when] [--global-one|-a] [--global-two|-b] [--] old [--stdout|-o message] [--stderr|-e message] [--] [Arguments ...]\n\nWarning: command \"old\" is deprecated and will be removed in version 43.0.0; use \"new\" instead\n\nGlobal Arguments:\n\n   -h, --help\n      print a help message and exit\n\n   -v, --version\n      print a version message and exit\n\n   --timeout duration\n      maximum time the command may run\n\n   --color when\n      when to use colors in output (choices: auto, always, never; default auto)\n\n   -a, --global-one\n      \n\n   -b, --global-two\n      \n\nCommand Arguments:\n\n   -o, --stdout message\n       (default write to stdout)\n\n   -e, --stderr message\n       (default write to stderr)\n\n   [Arguments ...]\n      arguments\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdoutBuffer bytes.Buffer
+			var stderrBuffer bytes.Buffer
+			stream := stream.NewIOStream(&stdoutBuffer, &stderrBuffer, nil)
+
+			secret := makeEchoCommand("secret").(*tCommand[echoStruct])
+			secret.MDesc.Hidden = true
+
+			old := makeEchoCommand("old").(*tCommand[echoStruct])
+			old.MDesc.Deprecated = &Deprecation{Replacement: "new", Removal: "43.0.0"}
+
+			program := makeProgram()
+			program.AbbreviateCommands = tt.abbreviate
+			program.Register(secret)
+			program.Register(old)
+			program.Register(makeEchoCommand("new"))
+			program.Register(makeEchoCommand("second"))
+
+			code, _ := exit.CodeFromError(program.Main(stream, "", tt.args))
+
+			if gotCode := uint8(code); gotCode != tt.wantCode {
+				t.Errorf("Program.Main() code = %v, wantCode %v", gotCode, tt.wantCode)
+			}
+			if gotStdout := stdoutBuffer.String(); gotStdout != tt.wantStdout {
+				t.Errorf("Program.Main() stdout = %q, wantStdout %q", gotStdout, tt.wantStdout)
+			}
+			if gotStderr := stderrBuffer.String(); gotStderr != tt.wantStderr {
+				t.Errorf("Program.Main() stderr = %q, wantStderr %q", gotStderr, tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestDeprecation_Notice(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		deprecation Deprecation
+		want        string
+	}{
+		{Deprecation{}, `command "old" is deprecated`},
+		{Deprecation{Removal: "2.0"}, `command "old" is deprecated and will be removed in version 2.0`},
+		{Deprecation{Replacement: "new"}, `command "old" is deprecated; use "new" instead`},
+		{Deprecation{Replacement: "new", Removal: "2.0"}, `command "old" is deprecated and will be removed in version 2.0; use "new" instead`},
+	}
+	for _, tt := range tests {
+		if got := tt.deprecation.Notice("old"); got != tt.want {
+			t.Errorf("Deprecation.Notice() = %q, want %q", got, tt.want)
+		}
+	}
+}
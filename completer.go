@@ -0,0 +1,234 @@
+//spellchecker:words goprogram
+package goprogram
+
+//spellchecker:words slices strings github goprogram meta
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.tkw01536.de/goprogram/meta"
+)
+
+//spellchecker:words nolint wrapcheck
+
+// CompleteCommand is the name of the hidden command used for dynamic completion.
+//
+// When a program is invoked as "exe __complete ARGS...", it does not run a command.
+// Instead it treats ARGS as a partial command line, the last element of which is the (possibly empty) word being completed.
+// It then writes candidates for this word to standard output, one per line.
+// Candidates are always filtered to those starting with the word being completed.
+//
+// Completion scripts generated by WriteCompletion use this command to complete values of positional arguments and flags.
+// CompleteCommand is never listed in help pages.
+const CompleteCommand = "__complete"
+
+// CompleterCommand represents a command that can dynamically complete positional arguments and flag values.
+type CompleterCommand[E any, P any, F any, R Requirement[F]] interface {
+	Command[E, P, F, R]
+
+	// Complete returns candidates for the positional argument or flag value described by request.
+	// Candidates not starting with request.Current are ignored.
+	//
+	// Complete is called on a command that flags and positionals preceding the completed word have been parsed into.
+	// Because the command line is incomplete, parsing is best-effort and parse errors are ignored.
+	// AfterParse is not called.
+	//
+	// The context holds the environment of the command, but BeforeCommand has not been called.
+	// It must return either nil or an error with an exit code.
+	Complete(context Context[E, P, F, R], request CompletionRequest) ([]string, error)
+}
+
+// CompletionRequest describes a word to be completed by a CompleterCommand.
+type CompletionRequest struct {
+	// Flag is the flag whose value is being completed.
+	// When a positional argument is being completed, Flag is nil.
+	Flag *meta.Flag
+
+	// Args holds the positional arguments preceding the word being completed.
+	Args []string
+
+	// Current is the (possibly empty) partial word being completed.
+	Current string
+}
+
+// complete implements dynamic completion for words.
+// See CompleteCommand for details.
+//
+//nolint:wrapcheck
+func (p Program[E, P, F, R]) complete(context Context[E, P, F, R], words []string, setupEnvironment func(context Context[E, P, F, R]) (E, error)) error {
+	candidates, err := p.completeCandidates(context, words, setupEnvironment)
+	if err != nil {
+		return err
+	}
+
+	for _, candidate := range candidates {
+		if _, err := context.Println(candidate); err != nil {
+			return fmt.Errorf("%w: %w", errProgramIO, err)
+		}
+	}
+	return nil
+}
+
+// completeCandidates returns the filtered candidates for completing words.
+//
+//nolint:wrapcheck
+func (p Program[E, P, F, R]) completeCandidates(context Context[E, P, F, R], words []string, setupEnvironment func(context Context[E, P, F, R]) (E, error)) ([]string, error) {
+	current := ""
+	if len(words) > 0 {
+		current = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	// parse global flags; when there is no command yet, we are completing global flags or the command itself
+	if err := p.parseProgramFlags(&context.Args, words, nil); err != nil || context.Args.Command == "" {
+		globals := p.globalOptions()
+		if flag, ok := valueFlag(words, globals); ok {
+			return filterCandidates(flag.Choices, current), nil
+		}
+		if strings.HasPrefix(current, "-") {
+			return filterCandidates(flagCandidates(globals), current), nil
+		}
+		return filterCandidates(append(p.children(""), p.Aliases()...), current), nil
+	}
+
+	// expand keywords, aliases and groups, the same way run does
+	if keyword, ok := p.keywords[context.Args.Command]; ok {
+		if err := keyword(&context.Args, &context.Args.pos); err != nil {
+			return nil, nil
+		}
+	}
+	command, err := p.expandAbbreviation("", context.Args.Command, true)
+	if err != nil {
+		return nil, nil
+	}
+	aliases, err := p.expandAliases(command)
+	if err != nil {
+		return nil, nil
+	}
+	pos := context.Args.pos
+	for _, alias := range aliases {
+		command, pos = alias.Invoke(pos)
+	}
+	if context.Args.Command, context.Args.pos, err = p.descendGroups(command, pos); err != nil {
+		return nil, nil
+	}
+
+	// we are completing the name of a command inside a group
+	if p.hasGroup(context.Args.Command) {
+		if len(context.Args.pos) > 0 || strings.HasPrefix(current, "-") {
+			return nil, nil
+		}
+		return filterCandidates(p.children(context.Args.Command), current), nil
+	}
+
+	cmd, ok := p.Command(context.Args.Command)
+	if !ok {
+		return nil, nil
+	}
+	context.Description = cmd.Description()
+	context.parser = p.commandParser(cmd)
+	flags := context.parser.Flags()
+
+	// find out what is being completed
+	request := CompletionRequest{Current: current}
+	prefix := ""
+	if flag, ok := valueFlag(context.Args.pos, flags); ok {
+		request.Flag = &flag
+		context.Args.pos = context.Args.pos[:len(context.Args.pos)-1]
+	} else if name, value, ok := strings.Cut(current, "="); ok && strings.HasPrefix(name, "-") {
+		flag, ok := findFlag(name, flags)
+		if !ok {
+			return nil, nil
+		}
+		request.Flag = &flag
+		request.Current = value
+		prefix = name + "="
+	} else if strings.HasPrefix(current, "-") {
+		return filterCandidates(flagCandidates(flags), current), nil
+	}
+	request.Args = positionalArgs(context.Args.pos, flags)
+
+	// parse whatever we have into the command, ignoring errors
+	_, _ = context.parser.ParseArgs(context.Args.pos)
+
+	var candidates []string
+	if request.Flag != nil {
+		candidates = request.Flag.Choices
+	}
+
+	if completer, ok := cmd.(CompleterCommand[E, P, F, R]); ok {
+		if context.Environment, err = setupEnvironment(context); err != nil {
+			return nil, err
+		}
+		if candidates, err = completer.Complete(context, request); err != nil {
+			return nil, err
+		}
+	}
+
+	candidates = filterCandidates(candidates, request.Current)
+	for i, candidate := range candidates {
+		candidates[i] = prefix + candidate
+	}
+	return candidates, nil
+}
+
+// valueFlag checks if the last element of words is a flag from flags that expects a separate value.
+// If so, returns the flag.
+func valueFlag(words []string, flags []meta.Flag) (meta.Flag, bool) {
+	if len(words) == 0 || slices.Contains(words, "--") {
+		return meta.Flag{}, false
+	}
+	flag, ok := findFlag(words[len(words)-1], flags)
+	if !ok || flag.Boolean {
+		return meta.Flag{}, false
+	}
+	return flag, true
+}
+
+// findFlag finds the flag from flags with the given name, including leading dashes.
+func findFlag(name string, flags []meta.Flag) (meta.Flag, bool) {
+	for _, flag := range flags {
+		if slices.Contains(flagNames(flag), name) {
+			return flag, true
+		}
+	}
+	return meta.Flag{}, false
+}
+
+// positionalArgs returns the positional arguments in words, skipping over flags from flags and their values.
+func positionalArgs(words []string, flags []meta.Flag) (args []string) {
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		switch {
+		case word == "--":
+			return append(args, words[i+1:]...)
+		case strings.HasPrefix(word, "-") && word != "-":
+			if flag, ok := findFlag(word, flags); ok && !flag.Boolean {
+				i++ // skip the value
+			}
+		default:
+			args = append(args, word)
+		}
+	}
+	return args
+}
+
+// flagCandidates returns the names of flags as completion candidates.
+func flagCandidates(flags []meta.Flag) (candidates []string) {
+	for _, flag := range flags {
+		candidates = append(candidates, flagNames(flag)...)
+	}
+	return candidates
+}
+
+// filterCandidates returns those candidates that start with prefix.
+func filterCandidates(candidates []string, prefix string) []string {
+	filtered := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			filtered = append(filtered, candidate)
+		}
+	}
+	return filtered
+}
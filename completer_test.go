@@ -0,0 +1,107 @@
+//spellchecker:words goprogram
+package goprogram //nolint:testpackage // tests internal behavior
+
+//spellchecker:words bytes testing github goprogram meta pkglib stream
+import (
+	"bytes"
+	"testing"
+
+	"go.tkw01536.de/goprogram/meta"
+	"go.tkw01536.de/pkglib/stream"
+)
+
+// completerCommand is a command that implements CompleterCommand.
+type completerCommand struct {
+	Remote string `long:"remote" short:"r"`
+	Level  string `choice:"debug"   choice:"info" long:"level"`
+	Force  bool   `long:"force"     short:"f"`
+
+	Positionals struct {
+		Args []string
+	} `positional-args:"true"`
+}
+
+func (*completerCommand) Description() iDescription {
+	return iDescription{
+		Command:      "fetch",
+		Requirements: func(flag meta.Flag) bool { return true },
+	}
+}
+func (*completerCommand) Run(context iContext) error { return nil }
+
+func (c *completerCommand) Complete(context iContext, request CompletionRequest) ([]string, error) {
+	switch {
+	case request.Flag == nil:
+		return append([]string{"apple", "avocado", "banana", "remote-" + c.Remote}, request.Args...), nil
+	case request.Flag.Long[0] == "remote":
+		return []string{"origin", "upstream", string(context.Environment)}, nil
+	default:
+		return request.Flag.Choices, nil
+	}
+}
+
+func TestProgram_Main_complete(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"no arguments", nil, "fetch\nrepo\nf\n"},
+		{"empty word", []string{""}, "fetch\nrepo\nf\n"},
+		{"command prefix", []string{"re"}, "repo\n"},
+		{"global flags", []string{"--g"}, "--global-one\n--global-two\n"},
+		{"global flag value", []string{"--global-one", ""}, ""},
+		{"after global flag", []string{"-a", "x", "f"}, "fetch\nf\n"},
+		{"group", []string{"repo", ""}, "clone\n"},
+		{"unknown command", []string{"nope", ""}, ""},
+		{"command flags", []string{"fetch", "--"}, "--remote\n--level\n--force\n"},
+		{"positional", []string{"fetch", "a"}, "apple\navocado\n"},
+		{"positional with flags", []string{"fetch", "-r", "origin", "--force", "one", "r"}, "remote-origin\n"},
+		{"positional args", []string{"fetch", "one", "two", ""}, "apple\navocado\nbanana\nremote-\none\ntwo\n"},
+		{"flag value with environment", []string{"fetch", "--remote", ""}, "origin\nupstream\nparams\n"},
+		{"short flag value", []string{"fetch", "-r", "up"}, "upstream\n"},
+		{"flag value with equals", []string{"fetch", "--remote=o"}, "--remote=origin\n"},
+		{"flag choices", []string{"fetch", "--level", ""}, "debug\ninfo\n"},
+		{"boolean flag", []string{"fetch", "--force", "b"}, "banana\n"},
+		{"alias", []string{"f", "--remote", "o"}, "origin\n"},
+		{"keyword", []string{"list", "l"}, ""},
+		{"command without completer", []string{"repo", "clone", "x"}, ""},
+		{"command without completer flag", []string{"repo", "clone", "--stdout", ""}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdoutBuffer bytes.Buffer
+			var stderrBuffer bytes.Buffer
+			stream := stream.NewIOStream(&stdoutBuffer, &stderrBuffer, nil)
+
+			program := makeProgram()
+			program.NewEnvironment = func(params tParameters, context iContext) (tEnvironment, error) {
+				return tEnvironment(params), nil
+			}
+			program.RegisterGroup(Group{Name: "repo"})
+			program.Register(&completerCommand{})
+			program.Register(makeEchoCommand("repo clone"))
+			program.RegisterAlias(Alias{Name: "f", Command: "fetch"})
+			program.RegisterKeyword("list", func(args *iArguments, pos *[]string) error {
+				args.Command = "fetch"
+				return nil
+			})
+
+			err := program.Main(stream, "params", append([]string{CompleteCommand}, tt.args...))
+			if err != nil {
+				t.Errorf("Program.Main() error = %v", err)
+			}
+			if got := stdoutBuffer.String(); got != tt.want {
+				t.Errorf("Program.Main() stdout = %q, want = %q", got, tt.want)
+			}
+			if got := stderrBuffer.String(); got != "" {
+				t.Errorf("Program.Main() stderr = %q, want = %q", got, "")
+			}
+		})
+	}
+}
@@ -0,0 +1,454 @@
+//spellchecker:words goprogram
+package goprogram
+
+//spellchecker:words strings text template essio shellescape github goprogram exit meta
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"al.essio.dev/pkg/shellescape"
+	"go.tkw01536.de/goprogram/exit"
+	"go.tkw01536.de/goprogram/meta"
+)
+
+//spellchecker:words compdef compadd compgen funcstack fpath opc COMPREPLY CWORD cmdpath
+
+// Shells supported by WriteCompletion.
+const (
+	ShellBash = "bash"
+	ShellZsh  = "zsh"
+	ShellFish = "fish"
+)
+
+// completionTemplates holds the templates for completion scripts of each supported shell.
+var completionTemplates = map[string]*template.Template{
+	ShellBash: template.Must(template.New(ShellBash).Parse(bashCompletionTemplate)),
+	ShellZsh:  template.Must(template.New(ShellZsh).Parse(zshCompletionTemplate)),
+	ShellFish: template.Must(template.New(ShellFish).Parse(fishCompletionTemplate)),
+}
+
+var errCompletionUnknownShell = exit.NewErrorWithCode("unknown shell", exit.ExitCommandArguments)
+
+// WriteCompletion writes a completion script for the given shell to w.
+// Supported shells are ShellBash, ShellZsh and ShellFish.
+//
+// The script is generated from the commands, groups, aliases and flags registered with this program.
+// It completes the names of commands, groups and aliases, the names of global and command flags, and the choices of flags.
+// It should be regenerated whenever any of these change.
+//
+// Positional arguments of commands and values of flags without choices are completed dynamically.
+// For this purpose, the script invokes the program using CompleteCommand.
+//
+// The returned error is nil, or of type exit.Error.
+func (p Program[E, P, F, R]) WriteCompletion(shell string, w io.Writer) error {
+	tpl, ok := completionTemplates[shell]
+	if !ok {
+		return fmt.Errorf("%w %q: must be one of %s", errCompletionUnknownShell, shell, meta.JoinCommands([]string{ShellBash, ShellFish, ShellZsh}))
+	}
+
+	if err := tpl.Execute(w, p.completionData()); err != nil {
+		return fmt.Errorf("%w: %w", errProgramIO, err)
+	}
+	return nil
+}
+
+// completionData holds information used to generate completion scripts.
+//
+// Fields ending in "Pattern" hold shell case patterns, fields ending in "List" hold space-separated lists of quoted words.
+// Fields ending in "Words" hold a single quoted word containing a space-separated list.
+type completionData struct {
+	Executable string // name of the executable
+	Function   string // prefix for shell functions
+	Complete   string // name of the hidden command used for dynamic completion
+
+	GroupPattern string // matches the names of the program and all groups
+	GroupList    string // names of the program and all groups
+
+	ValueGlobalPattern string // matches global flags that take a value, may be empty
+	ValueGlobalList    string // global flags that take a value, may be empty
+
+	Nodes []completionNode
+}
+
+// completionNode holds information about the completion of the program itself, a group, a command or an alias.
+type completionNode struct {
+	Path     string // the full name, quoted
+	PathList string // the words of the full name
+
+	Condition string // fish command checking if this node is being completed, quoted as a single word
+
+	Words     string // names of contained commands, groups and aliases
+	WordsList string
+
+	FlagWords string // names of flags
+	FlagList  string
+
+	Flags []completionFlag
+
+	Dynamic bool // should positionals and flag values be completed dynamically?
+}
+
+// completionFlag holds information about a single flag for completion.
+type completionFlag struct {
+	Pattern string // matches the names of the flag
+
+	Long, Short []string // long and short names of the flag
+	Value       bool     // does the flag take a value?
+	Usage       string   // usage, quoted
+
+	ChoiceWords string // valid choices, may be empty
+	ChoiceList  string
+}
+
+// completionData returns data to generate completion scripts from.
+func (p Program[E, P, F, R]) completionData() completionData {
+	data := completionData{
+		Executable: p.Info.Executable,
+		Function:   completionFunction(p.Info.Executable),
+		Complete:   CompleteCommand,
+	}
+
+	// the program itself, followed by all groups
+	groups := append([]string{""}, p.Groups()...)
+	data.GroupPattern = quoteJoin(groups, " | ")
+	data.GroupList = quoteJoin(groups, " ")
+
+	// global flags
+	globals := p.globalOptions()
+	var valueGlobals []string
+	for _, flag := range globals {
+		if !flag.Boolean {
+			valueGlobals = append(valueGlobals, flagNames(flag)...)
+		}
+	}
+	data.ValueGlobalPattern = quoteJoin(valueGlobals, " | ")
+	data.ValueGlobalList = quoteJoin(valueGlobals, " ")
+
+	// root and groups
+	for _, group := range groups {
+		words := p.children(group)
+		var flags []meta.Flag
+		if group == "" {
+			words = append(words, p.Aliases()...)
+			flags = globals
+		}
+		data.Nodes = append(data.Nodes, newCompletionNode(group, words, flags))
+	}
+
+	// commands, omitting hidden ones so that their names are not revealed by the script
+	for _, name := range p.listedCommands() {
+		command, _ := p.Command(name)
+		node := newCompletionNode(name, nil, p.commandParser(command).Flags())
+		node.Dynamic = true
+		data.Nodes = append(data.Nodes, node)
+	}
+
+	// aliases complete the flags of the command they expand to
+	for _, name := range p.Aliases() {
+		var flags []meta.Flag
+		if chain, err := p.expandAliases(name); err == nil {
+			if command, ok := p.Command(chain[len(chain)-1].Command); ok {
+				flags = p.commandParser(command).Flags()
+			}
+		}
+		node := newCompletionNode(name, nil, flags)
+		node.Dynamic = true
+		data.Nodes = append(data.Nodes, node)
+	}
+
+	// the condition is itself passed as a (quoted) word to fish, so it has to be quoted once more
+	for i := range data.Nodes {
+		data.Nodes[i].Condition = shellescape.Quote(data.Function + "_is " + data.Nodes[i].PathList)
+	}
+
+	return data
+}
+
+// newCompletionNode creates a new node for completion.
+func newCompletionNode(path string, words []string, flags []meta.Flag) completionNode {
+	node := completionNode{
+		Path:     shellescape.Quote(path),
+		PathList: quoteJoin(strings.Fields(path), " "),
+
+		Words:     shellescape.Quote(strings.Join(words, " ")),
+		WordsList: quoteJoin(words, " "),
+	}
+
+	var names []string
+	for _, flag := range flags {
+		names = append(names, flagNames(flag)...)
+
+		node.Flags = append(node.Flags, completionFlag{
+			Pattern: quoteJoin(flagNames(flag), " | "),
+
+			Long:  flag.Long,
+			Short: flag.Short,
+			Value: !flag.Boolean,
+			Usage: shellescape.Quote(flag.Usage),
+
+			ChoiceWords: shellescape.Quote(strings.Join(flag.Choices, " ")),
+			ChoiceList:  quoteJoin(flag.Choices, " "),
+		})
+	}
+	node.FlagWords = shellescape.Quote(strings.Join(names, " "))
+	node.FlagList = quoteJoin(names, " ")
+
+	return node
+}
+
+// flagNames returns the names of flag, including leading dashes.
+func flagNames(flag meta.Flag) []string {
+	names := make([]string, 0, len(flag.Long)+len(flag.Short))
+	for _, long := range flag.Long {
+		names = append(names, "--"+long)
+	}
+	for _, short := range flag.Short {
+		names = append(names, "-"+short)
+	}
+	return names
+}
+
+// quoteJoin quotes each of elems for use in a shell and joins them using sep.
+func quoteJoin(elems []string, sep string) string {
+	quoted := make([]string, len(elems))
+	for i, elem := range elems {
+		quoted[i] = shellescape.Quote(elem)
+	}
+	return strings.Join(quoted, sep)
+}
+
+// completionFunction returns the prefix for shell functions used by completion scripts for executable.
+func completionFunction(executable string) string {
+	return "_" + strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, executable) + "_completion"
+}
+
+const bashCompletionTemplate = `# bash completion for {{ .Executable }}
+#
+# This file has been generated automatically.
+# To use it, source it from within bash.
+
+# {{ .Function }}_dynamic asks {{ .Executable }} for candidates
+{{ .Function }}_dynamic() {
+	local IFS=$'\n'
+	COMPREPLY=($("${COMP_WORDS[0]}" {{ .Complete }} "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+
+{{ .Function }}() {
+	local cur="${COMP_WORDS[COMP_CWORD]}"
+	local prev="${COMP_WORDS[COMP_CWORD-1]}"
+
+	# find the command being completed
+	local cmdpath="" skip="" word i
+	for ((i = 1; i < COMP_CWORD; i++)); do
+		word="${COMP_WORDS[i]}"
+		if [[ -n "$skip" ]]; then
+			skip=""
+			continue
+		fi
+		case "$cmdpath" in
+		{{ .GroupPattern }}) ;;
+		*) break ;;
+		esac
+		case "$word" in
+		{{- if .ValueGlobalPattern }}
+		{{ .ValueGlobalPattern }}) [[ -z "$cmdpath" ]] && skip=1 ;;
+		{{- end }}
+		-*) ;;
+		*) cmdpath="${cmdpath:+$cmdpath }$word" ;;
+		esac
+	done
+
+	local words=""
+	case "$cmdpath" in
+	{{- range $node := .Nodes }}
+	{{ .Path }})
+		case "$prev" in
+		{{- range .Flags }}{{ if .Value }}
+		{{ .Pattern }})
+			{{- if .ChoiceList }}
+			COMPREPLY=($(compgen -W {{ .ChoiceWords }} -- "$cur"))
+			{{- else if $node.Dynamic }}
+			{{ $.Function }}_dynamic
+			{{- end }}
+			return
+			;;
+		{{- end }}{{ end }}
+		esac
+		if [[ "$cur" == -* ]]; then
+			words={{ .FlagWords }}
+		{{- if .Dynamic }}
+		else
+			{{ $.Function }}_dynamic
+			return
+		{{- else }}
+		else
+			words={{ .Words }}
+		{{- end }}
+		fi
+		;;
+	{{- end }}
+	esac
+
+	COMPREPLY=($(compgen -W "$words" -- "$cur"))
+}
+
+complete -o default -F {{ .Function }} {{ .Executable }}
+`
+
+const zshCompletionTemplate = `#compdef {{ .Executable }}
+
+# zsh completion for {{ .Executable }}
+#
+# This file has been generated automatically.
+# To use it, place it inside a directory in $fpath or source it from within zsh.
+
+# {{ .Function }}_dynamic asks {{ .Executable }} for candidates
+{{ .Function }}_dynamic() {
+	local -a candidates
+	candidates=(${(f)"$("${words[1]}" {{ .Complete }} "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+	if (( ${#candidates} )); then
+		compadd -a candidates
+	else
+		_files
+	fi
+}
+
+{{ .Function }}() {
+	local cur="${words[CURRENT]}"
+	local prev="${words[CURRENT-1]}"
+
+	# find the command being completed
+	local cmdpath="" skip="" word i
+	for ((i = 2; i < CURRENT; i++)); do
+		word="${words[i]}"
+		if [[ -n "$skip" ]]; then
+			skip=""
+			continue
+		fi
+		case "$cmdpath" in
+		{{ .GroupPattern }}) ;;
+		*) break ;;
+		esac
+		case "$word" in
+		{{- if .ValueGlobalPattern }}
+		{{ .ValueGlobalPattern }}) [[ -z "$cmdpath" ]] && skip=1 ;;
+		{{- end }}
+		-*) ;;
+		*) cmdpath="${cmdpath:+$cmdpath }$word" ;;
+		esac
+	done
+
+	local -a candidates
+	case "$cmdpath" in
+	{{- range $node := .Nodes }}
+	{{ .Path }})
+		case "$prev" in
+		{{- range .Flags }}{{ if .Value }}
+		{{ .Pattern }})
+			{{- if .ChoiceList }}
+			compadd -- {{ .ChoiceList }}
+			{{- else if $node.Dynamic }}
+			{{ $.Function }}_dynamic
+			{{- else }}
+			_files
+			{{- end }}
+			return
+			;;
+		{{- end }}{{ end }}
+		esac
+		if [[ "$cur" == -* ]]; then
+			candidates=({{ .FlagList }})
+		{{- if .Dynamic }}
+		else
+			{{ $.Function }}_dynamic
+			return
+		{{- else }}
+		else
+			candidates=({{ .WordsList }})
+		{{- end }}
+		fi
+		;;
+	{{- end }}
+	esac
+
+	if (( ${#candidates} )); then
+		compadd -a candidates
+	else
+		_files
+	fi
+}
+
+compdef {{ .Function }} {{ .Executable }}
+
+if [[ "${funcstack[1]}" == "{{ .Function }}" ]]; then
+	{{ .Function }} "$@"
+fi
+`
+
+const fishCompletionTemplate = `# fish completion for {{ .Executable }}
+#
+# This file has been generated automatically.
+# To use it, place it inside a directory in $fish_complete_path or source it from within fish.
+
+# {{ .Function }}_path prints the command being completed
+function {{ .Function }}_path
+	set -l tokens (commandline -opc)
+	set -e tokens[1]
+
+	set -l cmdpath ''
+	set -l skip 0
+	for word in $tokens
+		if test $skip -eq 1
+			set skip 0
+			continue
+		end
+		if not contains -- "$cmdpath" {{ .GroupList }}
+			break
+		end
+		switch $word
+		{{- if .ValueGlobalList }}
+			case {{ .ValueGlobalList }}
+				test -z "$cmdpath"; and set skip 1
+		{{- end }}
+			case '-*'
+			case '*'
+				if test -z "$cmdpath"
+					set cmdpath $word
+				else
+					set cmdpath "$cmdpath $word"
+				end
+		end
+	end
+	echo $cmdpath
+end
+
+# {{ .Function }}_is checks if the command being completed consists of the given words
+function {{ .Function }}_is
+	set -l cmdpath ({{ .Function }}_path)
+	test "$cmdpath" = "$argv"
+end
+
+# {{ .Function }}_dynamic asks {{ .Executable }} for candidates
+function {{ .Function }}_dynamic
+	set -l tokens (commandline -opc)
+	{{ .Executable }} {{ .Complete }} $tokens[2..-1] (commandline -ct) 2>/dev/null
+end
+{{ range $node := .Nodes }}
+{{- if .WordsList }}
+complete -c {{ $.Executable }} -n {{ $node.Condition }} -f -a {{ .Words }}
+{{- end }}
+{{- if .Dynamic }}
+complete -c {{ $.Executable }} -n {{ $node.Condition }} -a '({{ $.Function }}_dynamic)'
+{{- end }}
+{{- range .Flags }}
+complete -c {{ $.Executable }} -n {{ $node.Condition }}{{ range .Long }} -l {{ . }}{{ end }}{{ range .Short }} -s {{ . }}{{ end }}{{ if .ChoiceList }} -x -a {{ .ChoiceWords }}{{ else if and .Value $node.Dynamic }} -r -a '({{ $.Function }}_dynamic)'{{ else if .Value }} -r{{ end }} -d {{ .Usage }}
+{{- end }}
+{{- end }}
+`
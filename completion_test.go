@@ -0,0 +1,243 @@
+//spellchecker:words goprogram
+package goprogram //nolint:testpackage // tests internal behavior
+
+//spellchecker:words errors exec path filepath slices strings testing goprogram exit meta
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.tkw01536.de/goprogram/exit"
+	"go.tkw01536.de/goprogram/meta"
+)
+
+//spellchecker:words COMPREPLY CWORD
+
+// completionCommand is a command with flags used to test completion.
+type completionCommand struct {
+	Color   string `choice:"red"    choice:"blue" long:"color" short:"c"`
+	Output  string `long:"output"   value-name:"file"`
+	Verbose bool   `long:"verbose"  short:"v"`
+}
+
+func (completionCommand) Description() iDescription {
+	return iDescription{
+		Command:      "paint",
+		Requirements: func(flag meta.Flag) bool { return true },
+	}
+}
+func (completionCommand) AfterParse() error          { return nil }
+func (completionCommand) Run(context iContext) error { return nil }
+
+// makeCompletionProgram makes a program with groups, commands and aliases for completion.
+func makeCompletionProgram() iProgram {
+	p := makeProgram()
+	p.RegisterGroup(Group{Name: "repo"})
+	p.Register(completionCommand{})
+	p.Register(makeEchoCommand("repo list"))
+	p.Register(makeEchoCommand("repo show"))
+	p.RegisterAlias(Alias{Name: "p", Command: "paint"})
+	return p
+}
+
+func TestProgram_WriteCompletion_unknownShell(t *testing.T) {
+	t.Parallel()
+
+	p := makeCompletionProgram()
+
+	var buffer bytes.Buffer
+	err := p.WriteCompletion("cmd", &buffer)
+	if !errors.Is(err, errCompletionUnknownShell) {
+		t.Errorf("Program.WriteCompletion() error = %v, want errCompletionUnknownShell", err)
+	}
+	if code, _ := exit.CodeFromError(err); code != exit.ExitCommandArguments {
+		t.Errorf("Program.WriteCompletion() code = %v, want = %v", code, exit.ExitCommandArguments)
+	}
+	if buffer.Len() != 0 {
+		t.Errorf("Program.WriteCompletion() wrote %q, want nothing", buffer.String())
+	}
+}
+
+func TestProgram_WriteCompletion(t *testing.T) {
+	t.Parallel()
+
+	p := makeCompletionProgram()
+
+	tests := []struct {
+		shell    string
+		contains []string
+	}{
+		{
+			shell: ShellBash,
+			contains: []string{
+				"complete -o default -F _exe_completion exe",
+				"\tpaint)",
+				"'repo list')",
+				"words='paint repo p'",
+			},
+		},
+		{
+			shell: ShellZsh,
+			contains: []string{
+				"#compdef exe",
+				"compdef _exe_completion exe",
+				"compadd -- red blue",
+				"candidates=(paint repo p)",
+			},
+		},
+		{
+			shell: ShellFish,
+			contains: []string{
+				"complete -c exe -n '_exe_completion_is ' -f -a 'paint repo p'",
+				"complete -c exe -n '_exe_completion_is repo' -f -a 'list show'",
+				"complete -c exe -n '_exe_completion_is paint' -l color -s c -x -a 'red blue'",
+				"complete -c exe -n '_exe_completion_is p' -l verbose -s v",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			t.Parallel()
+
+			var buffer bytes.Buffer
+			if err := p.WriteCompletion(tt.shell, &buffer); err != nil {
+				t.Fatalf("Program.WriteCompletion() error = %v", err)
+			}
+
+			got := buffer.String()
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("Program.WriteCompletion() does not contain %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestProgram_WriteCompletion_hidden(t *testing.T) {
+	t.Parallel()
+
+	p := makeCompletionProgram()
+	hidden := makeEchoCommand("repo secret").(*tCommand[echoStruct])
+	hidden.MDesc.Hidden = true
+	p.Register(hidden)
+
+	for _, shell := range []string{ShellBash, ShellZsh, ShellFish} {
+		var buffer bytes.Buffer
+		if err := p.WriteCompletion(shell, &buffer); err != nil {
+			t.Fatalf("Program.WriteCompletion(%q) error = %v", shell, err)
+		}
+		if strings.Contains(buffer.String(), "secret") {
+			t.Errorf("Program.WriteCompletion(%q) contains name of hidden command", shell)
+		}
+	}
+}
+
+func TestProgram_WriteCompletion_bash(t *testing.T) {
+	t.Parallel()
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not found")
+	}
+
+	// write the completion script
+	script := filepath.Join(t.TempDir(), "completion.bash")
+	{
+		var buffer bytes.Buffer
+		if err := makeCompletionProgram().WriteCompletion(ShellBash, &buffer); err != nil {
+			t.Fatalf("Program.WriteCompletion() error = %v", err)
+		}
+		if err := os.WriteFile(script, buffer.Bytes(), 0o600); err != nil {
+			t.Fatalf("failed to write script: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"empty", "exe ", "paint repo p"},
+		{"command prefix", "exe pa", "paint"},
+		{"global flags", "exe --", "--help --version --timeout --color --global-one --global-two"},
+		{"global flag value", "exe --global-one value r", "repo"},
+		{"group", "exe repo ", "list show"},
+		{"group with prefix", "exe repo s", "show"},
+		{"command flags", "exe paint --", "--color --output --verbose"},
+		{"flag choices", "exe paint --color ", "red blue"},
+		{"flag choices prefix", "exe paint -c b", "blue"},
+		{"alias flags", "exe p --v", "--verbose"},
+		{"nested command flags", "exe repo list --std", "--stdout --stderr"},
+		{"dynamic positional", "exe paint x", "__complete paint x"},
+		{"dynamic flag value", "exe p --output ", "__complete p --output"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			words := strings.Fields(tt.line)
+			if strings.HasSuffix(tt.line, " ") {
+				words = append(words, "")
+			}
+
+			// run the completion, stubbing out the executable to print the arguments for dynamic completion
+			cmd := exec.Command(bash, "--norc", "--noprofile", "-c", `exe() { printf '%s\n' "$@"; }; source "$1"; shift; COMP_WORDS=("$@"); COMP_CWORD=$(($# - 1)); _exe_completion; echo "${COMPREPLY[*]}"`, "bash", script)
+			cmd.Args = append(cmd.Args, words...)
+
+			out, err := cmd.Output()
+			if err != nil {
+				t.Fatalf("failed to run bash: %v", err)
+			}
+			if got := strings.TrimSpace(string(out)); got != tt.want {
+				t.Errorf("completion of %q = %q, want = %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestProgram_completionData_fishCondition checks that conditions in the fish script survive being quoted twice.
+// Fish is not needed, as it interprets the quotes generated by shellescape like bash does.
+func TestProgram_completionData_fishCondition(t *testing.T) {
+	t.Parallel()
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not found")
+	}
+
+	program := makeProgram()
+	program.RegisterGroup(Group{Name: "it's"})
+	program.Register(makeEchoCommand(`it's "echo"`))
+
+	data := program.completionData()
+	for _, name := range []string{"it's", `it's "echo"`} {
+		words := strings.Fields(name)
+
+		index := slices.IndexFunc(data.Nodes, func(node completionNode) bool {
+			return node.PathList == quoteJoin(words, " ")
+		})
+		if index < 0 {
+			t.Fatalf("no completion node for %q", name)
+		}
+		condition := data.Nodes[index].Condition
+
+		// unquote the condition, and then the arguments of the function it calls
+		cmd := exec.Command(bash, "--norc", "--noprofile", "-c", `eval "set -- $1"; eval "set -- $1"; shift; printf '%s\n' "$@"`, "bash", condition)
+		output, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("bash failed: %v", err)
+		}
+
+		if got, want := string(output), strings.Join(words, "\n")+"\n"; got != want {
+			t.Errorf("condition %s for %q evaluates to arguments %q, want %q", condition, name, got, want)
+		}
+	}
+}
@@ -0,0 +1,501 @@
+//spellchecker:words goprogram
+package goprogram
+
+//spellchecker:words bytes encoding json errors maps path filepath regexp slices strconv strings time github pelletier toml unstable goprogram exit parser yaml
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pelletier/go-toml/v2/unstable"
+	"go.tkw01536.de/goprogram/exit"
+	"go.tkw01536.de/goprogram/parser"
+	"gopkg.in/yaml.v3"
+)
+
+//spellchecker:words XDG nolint nilnil
+
+// configUniversals holds the universal flag to pass a configuration file explicitly.
+// It is only added to the universal flags when Program.ConfigFile is set.
+type configUniversals struct {
+	Config string `description:"read default values of flags from file" long:"config" value-name:"file"`
+}
+
+var configOpts = parser.AllFlags[configUniversals]()
+
+var (
+	errConfigRead    = exit.NewErrorWithCode("unable to read configuration file", exit.ExitGeneralArguments)
+	errConfigInvalid = exit.NewErrorWithCode("invalid configuration file", exit.ExitGeneralArguments)
+
+	errConfigFormat         = errors.New("unknown format: must be one of \".json\", \".toml\", \".yaml\", \".yml\"")
+	errConfigRoot           = errors.New("expected a table of keys")
+	errConfigValue          = errors.New("expected a string, number, boolean or an array thereof")
+	errConfigTable          = errors.New("expected a value, not a table")
+	errConfigUnknownFlag    = errors.New("unknown flag")
+	errConfigUnknownCommand = errors.New("unknown command or group")
+)
+
+// configFile is a parsed configuration file, see Program.ConfigFile.
+type configFile struct {
+	path string
+	root *configNode
+}
+
+// configNode represents a single key of a configuration file.
+type configNode struct {
+	line     int                    // line the key is defined on
+	values   []string               // values of the key, nil for tables
+	children map[string]*configNode // keys inside the table, nil for values
+}
+
+// readConfig reads and checks the configuration file of this program, see ConfigFile.
+// Path is the file passed explicitly using the "--config" flag, if any.
+//
+// When configuration files are not enabled, or no file is found, returns nil.
+// Otherwise, the returned error is nil, or of type exit.Error.
+//
+//nolint:nilnil
+func (p Program[E, P, F, R]) readConfig(path string) (*configFile, error) {
+	if p.ConfigFile == "" {
+		return nil, nil
+	}
+	if path == "" {
+		path = p.findConfig()
+		if path == "" {
+			return nil, nil
+		}
+	}
+
+	config, err := parseConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// check global flags against a throwaway parser
+	var args Arguments[F]
+	if err := config.apply(p.argumentsParser(&args), "", "flags"); err != nil {
+		return nil, err
+	}
+
+	// check all the commands
+	if err := p.checkConfig(config, ""); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// findConfig returns the path to the configuration file of this program in the first configuration directory containing it.
+// If no such file exists, returns the empty string.
+func (p Program[E, P, F, R]) findConfig() string {
+	for _, dir := range configDirs() {
+		path := filepath.Join(dir, p.Info.Executable, p.ConfigFile)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// configDirs returns the directories to search for configuration files, in order of preference.
+// Following the XDG Base Directory Specification, these are $XDG_CONFIG_HOME (defaulting to "~/.config") followed by $XDG_CONFIG_DIRS (defaulting to "/etc/xdg").
+// Relative directories are ignored.
+func configDirs() (dirs []string) {
+	home := os.Getenv("XDG_CONFIG_HOME")
+	if home == "" {
+		if user, err := os.UserHomeDir(); err == nil {
+			home = filepath.Join(user, ".config")
+		}
+	}
+	if filepath.IsAbs(home) {
+		dirs = append(dirs, home)
+	}
+
+	system := os.Getenv("XDG_CONFIG_DIRS")
+	if system == "" {
+		system = "/etc/xdg"
+	}
+	for dir := range strings.SplitSeq(system, string(os.PathListSeparator)) {
+		if filepath.IsAbs(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// checkConfig checks that every table inside the section of config belonging to the given group refers to a command or group.
+// Keys belonging to commands are checked against their flags.
+func (p Program[E, P, F, R]) checkConfig(config *configFile, group string) error {
+	node := config.section(group)
+	for _, key := range slices.Sorted(maps.Keys(node.children)) {
+		child := node.children[key]
+		name := joinName(group, key)
+
+		switch {
+		case child.children == nil && group == "":
+			// global flag, already checked
+		case child.children == nil:
+			return config.keyError(configKey(group, key), child.line, errConfigUnknownFlag)
+		case p.hasGroup(name):
+			if err := p.checkConfig(config, name); err != nil {
+				return err
+			}
+		case p.commands[name] != nil:
+			if err := config.apply(p.commandParser(p.commands[name]), name); err != nil {
+				return err
+			}
+		default:
+			return config.keyError(configKey(group, key), child.line, errConfigUnknownCommand)
+		}
+	}
+	return nil
+}
+
+// apply sets the default values of options of parser to the values found in the given section of c.
+// Section is the name of a command, or the empty string for global flags.
+// Groups restrict the options considered, see parser.SetDefault.
+//
+// When c is nil, or has no such section, apply does nothing.
+func (c *configFile) apply(parser parser.Parser, section string, groups ...string) error {
+	if c == nil {
+		return nil
+	}
+	node := c.section(section)
+	if node == nil {
+		return nil
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(node.children)) {
+		child := node.children[key]
+		if child.children != nil {
+			if section == "" {
+				continue // commands and groups, see Program.checkConfig
+			}
+			return c.keyError(configKey(section, key), child.line, errConfigTable)
+		}
+
+		ok, err := parser.SetDefault(key, child.values, groups...)
+		if err != nil {
+			return c.keyError(configKey(section, key), child.line, fmt.Errorf("invalid value: %w", err))
+		}
+		if !ok {
+			return c.keyError(configKey(section, key), child.line, errConfigUnknownFlag)
+		}
+	}
+	return nil
+}
+
+// section returns the table belonging to the command or group with the given name.
+// If there is no such table, returns nil.
+func (c *configFile) section(name string) *configNode {
+	node := c.root
+	for word := range strings.FieldsSeq(name) {
+		node = node.children[word]
+		if node == nil || node.children == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// keyError returns an error for the key with the given name, defined on the given line of c.
+func (c *configFile) keyError(name string, line int, err error) error {
+	return fmt.Errorf("%w %s:%d: key %q: %w", errConfigInvalid, c.path, line, name, err)
+}
+
+// configKey returns the dotted name of the key inside the section belonging to the given command or group.
+func configKey(section, key string) string {
+	return strings.Join(append(strings.Fields(section), key), ".")
+}
+
+// configFormats maps file extensions to functions parsing configuration files of the corresponding format.
+// Each function returns the root table, or the line an error occurred on (if known) and the error.
+var configFormats = map[string]func(data []byte) (*configNode, int, error){
+	".json": parseJSONConfig,
+	".toml": parseTOMLConfig,
+	".yaml": parseYAMLConfig,
+	".yml":  parseYAMLConfig,
+}
+
+// parseConfig reads and parses the configuration file at path.
+// The format of the file is determined by its extension.
+//
+// The returned error is nil, or of type exit.Error.
+func parseConfig(path string) (*configFile, error) {
+	parse, ok := configFormats[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return nil, fmt.Errorf("%w %s: %w", errConfigRead, path, errConfigFormat)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errConfigRead, err)
+	}
+
+	root, line, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w %s:%d: %w", errConfigInvalid, path, line, err)
+	}
+	return &configFile{path: path, root: root}, nil
+}
+
+// configScalar returns the string representation of a scalar value decoded from a configuration file.
+func configScalar(value any) (string, bool) {
+	switch value := value.(type) {
+	case string:
+		return value, true
+	case bool:
+		return strconv.FormatBool(value), true
+	case int64:
+		return strconv.FormatInt(value, 10), true
+	case float64:
+		return strconv.FormatFloat(value, 'g', -1, 64), true
+	case json.Number:
+		return value.String(), true
+	case time.Time:
+		return value.Format(time.RFC3339Nano), true
+	case json.Delim: // nested arrays and objects, checked before fmt.Stringer which it implements
+		return "", false
+	case fmt.Stringer: // local dates and times
+		return value.String(), true
+	}
+	return "", false
+}
+
+// parseJSONConfig parses a configuration file in JSON format.
+func parseJSONConfig(data []byte) (*configNode, int, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	// line returns the line the decoder is currently on.
+	line := func() int {
+		return 1 + bytes.Count(data[:decoder.InputOffset()], []byte("\n"))
+	}
+
+	var decode func(name string) (*configNode, error)
+	decode = func(name string) (*configNode, error) {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		node := &configNode{line: line()}
+		switch token {
+		case json.Delim('{'):
+			node.children = make(map[string]*configNode)
+			for decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				keyLine := line()
+
+				child, err := decode(configKey(name, key.(string)))
+				if err != nil {
+					return nil, err
+				}
+				child.line = keyLine
+				node.children[key.(string)] = child
+			}
+		case json.Delim('['):
+			node.values = []string{}
+			for decoder.More() {
+				token, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				value, ok := configScalar(token)
+				if !ok {
+					return nil, fmt.Errorf("key %q: %w", name, errConfigValue)
+				}
+				node.values = append(node.values, value)
+			}
+		default:
+			value, ok := configScalar(token)
+			if !ok {
+				return nil, fmt.Errorf("key %q: %w", name, errConfigValue)
+			}
+			return &configNode{line: node.line, values: []string{value}}, nil
+		}
+
+		// consume the closing delimiter
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	}
+
+	root, err := decode("")
+	if err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return nil, 1 + bytes.Count(data[:syntaxErr.Offset], []byte("\n")), err
+		}
+		return nil, line(), err
+	}
+	if root.children == nil {
+		return nil, root.line, errConfigRoot
+	}
+	return root, 0, nil
+}
+
+// parseTOMLConfig parses a configuration file in TOML format.
+func parseTOMLConfig(data []byte) (*configNode, int, error) {
+	var document map[string]any
+	if err := toml.Unmarshal(data, &document); err != nil {
+		var decodeErr *toml.DecodeError
+		if errors.As(err, &decodeErr) {
+			line, _ := decodeErr.Position()
+			return nil, line, err
+		}
+		return nil, 0, err
+	}
+
+	lines := tomlLines(data)
+
+	var convert func(name string, value any) (*configNode, int, error)
+	convert = func(name string, value any) (*configNode, int, error) {
+		node := &configNode{line: lines[name]}
+		switch value := value.(type) {
+		case map[string]any:
+			node.children = make(map[string]*configNode, len(value))
+			for key, child := range value {
+				var line int
+				var err error
+				if node.children[key], line, err = convert(configKey(name, key), child); err != nil {
+					return nil, line, err
+				}
+			}
+		case []any:
+			node.values = make([]string, len(value))
+			for i, element := range value {
+				var ok bool
+				if node.values[i], ok = configScalar(element); !ok {
+					return nil, node.line, fmt.Errorf("key %q: %w", name, errConfigValue)
+				}
+			}
+		default:
+			scalar, ok := configScalar(value)
+			if !ok {
+				return nil, node.line, fmt.Errorf("key %q: %w", name, errConfigValue)
+			}
+			node.values = []string{scalar}
+		}
+		return node, 0, nil
+	}
+
+	return convert("", document)
+}
+
+// tomlLines returns the line each key of a TOML document is first defined on, indexed by the dotted name of the key.
+func tomlLines(data []byte) map[string]int {
+	lines := make(map[string]int)
+
+	var p unstable.Parser
+	p.Reset(data)
+
+	var table []string
+	for p.NextExpression() {
+		expression := p.Expression()
+		switch expression.Kind {
+		case unstable.Table, unstable.ArrayTable:
+			table = tomlRecordLines(&p, lines, nil, expression.Key())
+		case unstable.KeyValue:
+			tomlRecordLines(&p, lines, table, expression.Key())
+		default:
+		}
+	}
+	return lines
+}
+
+// tomlRecordLines records the line of each part of a key inside the given table in lines.
+// It returns the full path of the key.
+func tomlRecordLines(p *unstable.Parser, lines map[string]int, table []string, key unstable.Iterator) []string {
+	path := slices.Clone(table)
+	for key.Next() {
+		node := key.Node()
+		path = append(path, string(node.Data))
+
+		name := strings.Join(path, ".")
+		if _, ok := lines[name]; !ok {
+			lines[name] = p.Shape(node.Raw).Start.Line
+		}
+	}
+	return path
+}
+
+// yamlErrorLine matches the line number inside errors returned by the yaml package.
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+// parseYAMLConfig parses a configuration file in YAML format.
+func parseYAMLConfig(data []byte) (*configNode, int, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		var line int
+		if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+			line, _ = strconv.Atoi(match[1])
+		}
+		return nil, line, err
+	}
+
+	// an empty document has no keys
+	if len(document.Content) == 0 {
+		return &configNode{children: make(map[string]*configNode)}, 0, nil
+	}
+
+	var convert func(name string, node *yaml.Node) (*configNode, int, error)
+	convert = func(name string, node *yaml.Node) (*configNode, int, error) {
+		for node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+
+		result := &configNode{line: node.Line}
+		switch node.Kind {
+		case yaml.MappingNode:
+			result.children = make(map[string]*configNode, len(node.Content)/2)
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key := node.Content[i]
+				child, line, err := convert(configKey(name, key.Value), node.Content[i+1])
+				if err != nil {
+					return nil, line, err
+				}
+				child.line = key.Line
+				result.children[key.Value] = child
+			}
+		case yaml.SequenceNode:
+			result.values = make([]string, len(node.Content))
+			for i, element := range node.Content {
+				if element.Kind != yaml.ScalarNode || element.Tag == "!!null" {
+					return nil, element.Line, fmt.Errorf("key %q: %w", name, errConfigValue)
+				}
+				result.values[i] = element.Value
+			}
+		case yaml.ScalarNode:
+			if node.Tag == "!!null" {
+				return nil, node.Line, fmt.Errorf("key %q: %w", name, errConfigValue)
+			}
+			result.values = []string{node.Value}
+		default:
+			return nil, node.Line, fmt.Errorf("key %q: %w", name, errConfigValue)
+		}
+		return result, 0, nil
+	}
+
+	root, line, err := convert("", document.Content[0])
+	if err != nil {
+		return nil, line, err
+	}
+	if root.children == nil {
+		return nil, root.line, errConfigRoot
+	}
+	return root, 0, nil
+}
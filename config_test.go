@@ -0,0 +1,266 @@
+//spellchecker:words goprogram
+package goprogram //nolint:testpackage // tests internal behavior
+
+//spellchecker:words bytes path filepath strings testing github goprogram exit meta pkglib stream
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.tkw01536.de/goprogram/exit"
+	"go.tkw01536.de/goprogram/meta"
+	"go.tkw01536.de/pkglib/stream"
+)
+
+//spellchecker:words paralleltest
+
+// makeConfigProgram makes a new program reading configuration files.
+// It registers a command "cmd" (also inside the group "repo") printing its flags.
+func makeConfigProgram() iProgram {
+	program := makeProgram()
+	program.ConfigFile = "config.toml"
+
+	makeCmd := func(name string) iCommand {
+		return &tCommand[struct{}]{
+			MDesc: iDescription{
+				Command:      name,
+				Requirements: func(flag meta.Flag) bool { return true },
+			},
+			MAfterParse: func() error { return nil },
+			MRun: func(command tCommand[struct{}], context iContext) error {
+				_, err := context.Printf("global: %q, stdout: %q\n", context.Args.Flags.GlobalOne, command.StdoutMsg)
+				return err
+			},
+		}
+	}
+
+	program.RegisterGroup(Group{Name: "repo"})
+	program.Register(makeCmd("cmd"))
+	program.Register(makeCmd("repo cmd"))
+	return program
+}
+
+// runConfigProgram runs program with the given arguments, and returns the exit code, standard output and standard error.
+func runConfigProgram(program iProgram, args ...string) (code uint8, stdout, stderr string) {
+	var stdoutBuffer bytes.Buffer
+	var stderrBuffer bytes.Buffer
+	stream := stream.NewIOStream(&stdoutBuffer, &stderrBuffer, nil)
+
+	exitCode, _ := exit.CodeFromError(program.Main(stream, "", args))
+	return uint8(exitCode), stdoutBuffer.String(), stderrBuffer.String()
+}
+
+func TestProgram_Main_config(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		args    []string
+
+		wantStdout string
+		wantStderr string // "${file}" is replaced by the path to the configuration file
+		wantCode   uint8
+	}{
+		{
+			name:       "toml",
+			file:       "config.toml",
+			content:    "global-one = \"from-file\"\n\n[cmd]\nstdout = \"stdout-from-file\"\n",
+			args:       []string{"cmd"},
+			wantStdout: "global: \"from-file\", stdout: \"stdout-from-file\"\n",
+		},
+		{
+			name:       "json",
+			file:       "config.json",
+			content:    "{\n  \"global-one\": \"from-file\",\n  \"cmd\": {\"stdout\": \"stdout-from-file\"}\n}\n",
+			args:       []string{"cmd"},
+			wantStdout: "global: \"from-file\", stdout: \"stdout-from-file\"\n",
+		},
+		{
+			name:       "yaml",
+			file:       "config.yaml",
+			content:    "global-one: from-file\ncmd:\n  stdout: stdout-from-file\n",
+			args:       []string{"cmd"},
+			wantStdout: "global: \"from-file\", stdout: \"stdout-from-file\"\n",
+		},
+		{
+			name:       "command inside group",
+			file:       "config.toml",
+			content:    "[repo.cmd]\nstdout = \"stdout-from-file\"\n",
+			args:       []string{"repo", "cmd"},
+			wantStdout: "global: \"\", stdout: \"stdout-from-file\"\n",
+		},
+		{
+			name:       "arguments override file",
+			file:       "config.toml",
+			content:    "global-one = \"from-file\"\n\n[cmd]\nstdout = \"stdout-from-file\"\n",
+			args:       []string{"--global-one", "from-args", "cmd", "--stdout", "stdout-from-args"},
+			wantStdout: "global: \"from-args\", stdout: \"stdout-from-args\"\n",
+		},
+		{
+			name:       "help shows values from file",
+			file:       "config.toml",
+			content:    "[cmd]\nstdout = \"stdout-from-file\"\n",
+			args:       []string{"cmd", "--help"},
+			wantStdout: "Usage: exe [--help|-h] [--version|-v] [--timeout duration] [--color when] [--config file] [--global-one|-a] [--global-two|-b] [--] cmd [--stdout|-o message] [--stderr|-e message]\n\nGlobal Arguments:\n\n   -h, --help\n      print a help message and exit\n\n   -v, --version\n      print a version message and exit\n\n   --timeout duration\n      maximum time the command may run\n\n   --color when\n      when to use colors in output (choices: auto, always, never; default auto)\n\n   --config file\n      read default values of flags from file\n\n   -a, --global-one\n      \n\n   -b, --global-two\n      \n\nCommand Arguments:\n\n   -o, --stdout message\n       (default stdout-from-file)\n\n   -e, --stderr message\n       (default write to stderr)\n",
+		},
+		{
+			name:       "unknown global flag",
+			file:       "config.toml",
+			content:    "global-one = \"from-file\"\nglobal-three = \"from-file\"\n",
+			args:       []string{"cmd"},
+			wantStderr: "invalid configuration file ${file}:2: key \"global-three\": unknown flag\n",
+			wantCode:   3,
+		},
+		{
+			name:       "universal flags are not configurable",
+			file:       "config.yaml",
+			content:    "help: true\n",
+			args:       []string{"cmd"},
+			wantStderr: "invalid configuration file ${file}:1: key \"help\": unknown flag\n",
+			wantCode:   3,
+		},
+		{
+			name:       "unknown command flag",
+			file:       "config.json",
+			content:    "{\n  \"cmd\": {\n    \"stdout\": \"stdout-from-file\",\n    \"stdin\": \"stdin-from-file\"\n  }\n}\n",
+			args:       []string{"cmd"},
+			wantStderr: "invalid configuration file ${file}:4: key \"cmd.stdin\": unknown flag\n",
+			wantCode:   3,
+		},
+		{
+			name:       "unknown command",
+			file:       "config.toml",
+			content:    "[cmd]\nstdout = \"stdout-from-file\"\n\n[repo.other]\nstdout = \"stdout-from-file\"\n",
+			args:       []string{"cmd"},
+			wantStderr: "invalid configuration file ${file}:4: key \"repo.other\": unknown command or group\n",
+			wantCode:   3,
+		},
+		{
+			name:       "nested table inside command",
+			file:       "config.yaml",
+			content:    "cmd:\n  stdout:\n    value: 1\n",
+			args:       []string{"cmd"},
+			wantStderr: "invalid configuration file ${file}:2: key \"cmd.stdout\": expected a value, not a table\n",
+			wantCode:   3,
+		},
+		{
+			name:       "missing value",
+			file:       "config.yaml",
+			content:    "global-one: from-file\ncmd:\n  stdout:\n",
+			args:       []string{"cmd"},
+			wantStderr: "invalid configuration file ${file}:3: key \"cmd.stdout\": expected a string, number, boolean or an array thereof\n",
+			wantCode:   3,
+		},
+		{
+			name:       "nested array",
+			file:       "config.json",
+			content:    "{\n  \"global-one\": [[1]]\n}\n",
+			args:       []string{"cmd"},
+			wantStderr: "invalid configuration file ${file}:2: key \"global-one\": expected a string, number, boolean or an array thereof\n",
+			wantCode:   3,
+		},
+		{
+			name:       "object inside array",
+			file:       "config.json",
+			content:    "{\n  \"global-one\": [{}]\n}\n",
+			args:       []string{"cmd"},
+			wantStderr: "invalid configuration file ${file}:2: key \"global-one\": expected a string, number, boolean or an array thereof\n",
+			wantCode:   3,
+		},
+		{
+			name:       "syntax error",
+			file:       "config.toml",
+			content:    "global-one = \"from-file\"\nglobal-two = \n",
+			args:       []string{"cmd"},
+			wantStderr: "invalid configuration file ${file}:2: toml: incomplete number\n",
+			wantCode:   3,
+		},
+		{
+			name:       "unknown format",
+			file:       "config.ini",
+			content:    "global-one = from-file\n",
+			args:       []string{"cmd"},
+			wantStderr: "unable to read configuration file ${file}: unknown format: must be one of \".json\", \".toml\", \".yaml\", \".yml\"\n",
+			wantCode:   3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatalf("failed to write configuration file: %v", err)
+			}
+
+			code, stdout, stderr := runConfigProgram(makeConfigProgram(), append([]string{"--config", path}, tt.args...)...)
+			if code != tt.wantCode {
+				t.Errorf("Program.Main() code = %v, wantCode %v", code, tt.wantCode)
+			}
+			if stdout != tt.wantStdout {
+				t.Errorf("Program.Main() stdout = %q, wantStdout %q", stdout, tt.wantStdout)
+			}
+			if wantStderr := strings.ReplaceAll(tt.wantStderr, "${file}", path); stderr != wantStderr {
+				t.Errorf("Program.Main() stderr = %q, wantStderr %q", stderr, wantStderr)
+			}
+		})
+	}
+}
+
+func TestProgram_Main_configDisabled(t *testing.T) {
+	t.Parallel()
+
+	program := makeConfigProgram()
+	program.ConfigFile = ""
+
+	code, _, stderr := runConfigProgram(program, "--config", "config.toml", "cmd")
+	if code != 3 {
+		t.Errorf("Program.Main() code = %v, want 3", code)
+	}
+	if want := "unable to parse arguments: unknown flag `config'\n"; stderr != want {
+		t.Errorf("Program.Main() stderr = %q, want %q", stderr, want)
+	}
+}
+
+//nolint:paralleltest // uses t.Setenv
+func TestProgram_Main_configLookup(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("XDG_CONFIG_DIRS", "")
+	t.Setenv("EXE_CMD_STDOUT", "stdout-from-env")
+
+	if err := os.Mkdir(filepath.Join(home, "exe"), 0o700); err != nil {
+		t.Fatalf("failed to create configuration directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(home, "exe", "config.toml"), []byte("global-one = \"from-file\"\n\n[cmd]\nstdout = \"stdout-from-file\"\n"), 0o600); err != nil {
+		t.Fatalf("failed to write configuration file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		autoEnv bool
+
+		wantStdout string
+	}{
+		{"file found in XDG_CONFIG_HOME", false, "global: \"from-file\", stdout: \"stdout-from-file\"\n"},
+		{"environment overrides file", true, "global: \"from-file\", stdout: \"stdout-from-env\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program := makeConfigProgram()
+			program.AutoEnv = tt.autoEnv
+
+			code, stdout, stderr := runConfigProgram(program, "cmd")
+			if code != 0 {
+				t.Errorf("Program.Main() code = %v, stderr = %q", code, stderr)
+			}
+			if stdout != tt.wantStdout {
+				t.Errorf("Program.Main() stdout = %q, wantStdout %q", stdout, tt.wantStdout)
+			}
+		})
+	}
+}
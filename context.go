@@ -1,11 +1,13 @@
 //spellchecker:words goprogram
 package goprogram
 
-//spellchecker:words context github goprogram parser pkglib stream
+//spellchecker:words context time github goprogram parser terminal pkglib stream
 import (
 	"context"
+	"time"
 
 	"go.tkw01536.de/goprogram/parser"
+	"go.tkw01536.de/goprogram/terminal"
 	"go.tkw01536.de/pkglib/stream"
 )
 
@@ -34,6 +36,9 @@ type Context[E any, P any, F any, R Requirement[F]] struct {
 	// this refers to the command itself
 	parser parser.Parser
 
+	// config holds the configuration file in use (if any)
+	config *configFile
+
 	// inExec indicates if the current command is being called from within a program.Exec call.
 	inExec bool
 
@@ -119,6 +124,11 @@ func (context Context[E, P, F, R]) Exec(command string, args ...string) error {
 	return context.Program.Exec(context, command, args...)
 }
 
+// RunScript is like context.Program.RunScript.
+func (context Context[E, P, F, R]) RunScript(path string) ([]ScriptResult, error) {
+	return context.Program.RunScript(context, path)
+}
+
 // Arguments represent a set of command-independent arguments passed to a command.
 // These should be further parsed into CommandArguments using the appropriate Parse() method.
 //
@@ -129,12 +139,16 @@ type Arguments[F any] struct {
 
 	Command string   // command to run
 	pos     []string // positional arguments
+
+	config configUniversals // flags used only when configuration files are enabled
 }
 
 // Universals holds flags added to every executable.
 //
 // Command line arguments are annotated using syntax provided by "github.com/jessevdk/go-flags".
 type Universals struct {
-	Help    bool `description:"print a help message and exit"    long:"help"    short:"h"`
-	Version bool `description:"print a version message and exit" long:"version" short:"v"`
+	Help    bool               `description:"print a help message and exit"    long:"help"    short:"h"`
+	Version bool               `description:"print a version message and exit" long:"version" short:"v"`
+	Timeout time.Duration      `description:"maximum time the command may run"  long:"timeout" value-name:"duration"`
+	Color   terminal.ColorMode `choice:"auto" choice:"always" choice:"never" default:"auto" description:"when to use colors in output" long:"color" value-name:"when"`
 }
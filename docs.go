@@ -0,0 +1,315 @@
+//spellchecker:words goprogram
+package goprogram
+
+//spellchecker:words html template path filepath slices strings text github goprogram exit meta
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"go.tkw01536.de/goprogram/exit"
+	"go.tkw01536.de/goprogram/meta"
+)
+
+// Formats supported by WriteDocs.
+const (
+	DocsMarkdown = "markdown"
+	DocsHTML     = "html"
+)
+
+// docsTemplate is a template for a single page of documentation.
+type docsTemplate interface {
+	Execute(w io.Writer, data any) error
+}
+
+// docsFormats holds the templates and file extensions for each supported documentation format.
+var docsFormats = map[string]struct {
+	template  docsTemplate
+	extension string
+}{
+	DocsMarkdown: {template.Must(template.New(DocsMarkdown).Parse(markdownDocsTemplate)), ".md"},
+	DocsHTML:     {htmltemplate.Must(htmltemplate.New(DocsHTML).Parse(htmlDocsTemplate)), ".html"},
+}
+
+var (
+	errDocsUnknownFormat = exit.NewErrorWithCode("unknown documentation format", exit.ExitCommandArguments)
+	errDocsWrite         = exit.NewErrorWithCode("unable to write documentation", exit.ExitGeneric)
+)
+
+// docsIndex is the name of the index page of the documentation, without extension.
+const docsIndex = "index"
+
+// WriteDocs writes reference documentation for this program into dir.
+// Format must be one of DocsMarkdown or DocsHTML.
+//
+// Documentation consists of an index page, a page for every group and a page for every command.
+// The index page describes the program, its global flags, and links to all commands, groups and aliases.
+// Every other page is named after meta.PageName.
+// Pages link to each other and to the global flags on the index page using anchor links.
+// Existing files are overwritten.
+//
+// The returned error is nil, or of type exit.Error.
+func (p Program[E, P, F, R]) WriteDocs(dir string, format string) error {
+	docsFormat, ok := docsFormats[format]
+	if !ok {
+		return fmt.Errorf("%w %q: must be one of %s", errDocsUnknownFormat, format, meta.JoinCommands([]string{DocsHTML, DocsMarkdown}))
+	}
+
+	names := append([]string{""}, p.Groups()...)
+	names = append(names, p.listedCommands()...)
+
+	for _, name := range names {
+		page := p.docsPage(name, docsFormat.extension)
+
+		file := docsIndex
+		if name != "" {
+			file = meta.PageName(p.Info.Executable, name)
+		}
+		path := filepath.Join(dir, file+docsFormat.extension)
+
+		if err := writeFile(path, errDocsWrite, func(w io.Writer) error {
+			if err := docsFormat.template.Execute(w, page); err != nil {
+				return fmt.Errorf("%w: %w", errDocsWrite, err)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// docsPage holds information to render a single page of documentation.
+type docsPage struct {
+	Title       string // name of the program, group or command
+	Description string
+	Synopsis    string
+
+	Index string // link to the index page, empty on the index page itself
+
+	GlobalFlags []docsEntry
+	Flags       []docsEntry
+	Positionals []docsEntry
+	Commands    []docsEntry
+	Aliases     []docsEntry
+}
+
+// docsEntry is a single entry in a list on a page of documentation.
+type docsEntry struct {
+	Anchor  string // anchor of the entry (if any)
+	Link    string // link to the place where the entry is documented (if any)
+	Name    string // name or specification of the entry
+	Usage   string // human-readable description
+	Details string // further details, such as choices and defaults
+}
+
+// docsPage generates the page of documentation for the program (when name is empty), or the group or command with the given name.
+// Extension is used to generate links to other pages.
+func (p Program[E, P, F, R]) docsPage(name string, extension string) (page docsPage) {
+	usage, _ := p.usage(name) // name is always a valid group or command
+	isCommand := usage.IsCommand()
+
+	page.Title = strings.TrimSpace(usage.Executable + " " + usage.Command)
+	page.Description = usage.Description
+
+	// synopsis
+	synopsis := []string{usage.Executable}
+	if name == "" {
+		for _, flag := range usage.GlobalFlags {
+			synopsis = append(synopsis, docsString(flag.WriteSpecTo))
+		}
+		synopsis = append(synopsis, "[--]")
+	} else if len(usage.GlobalFlags) > 0 {
+		synopsis = append(synopsis, "[GLOBAL FLAGS]")
+	}
+	if usage.Command != "" {
+		synopsis = append(synopsis, usage.Command)
+	}
+	if isCommand {
+		for _, flag := range usage.CommandFlags {
+			synopsis = append(synopsis, docsString(flag.WriteSpecTo))
+		}
+		if len(usage.Positionals) > 0 {
+			synopsis = append(synopsis, "[--]")
+		}
+		for _, pos := range usage.Positionals {
+			synopsis = append(synopsis, docsString(pos.WriteSpecTo))
+		}
+	} else {
+		synopsis = append(synopsis, "COMMAND [ARGS...]")
+	}
+	page.Synopsis = strings.Join(synopsis, " ")
+
+	// global flags are documented on the index page, and linked from everywhere else
+	if name != "" {
+		page.Index = docsIndex + extension
+	}
+	for _, flag := range usage.GlobalFlags {
+		entry := docsFlag(flag)
+		if name != "" {
+			entry.Link = page.Index + "#" + entry.Anchor
+			entry.Anchor = ""
+		}
+		page.GlobalFlags = append(page.GlobalFlags, entry)
+	}
+
+	if isCommand {
+		for _, flag := range usage.CommandFlags {
+			page.Flags = append(page.Flags, docsFlag(flag))
+		}
+		for _, pos := range usage.Positionals {
+			page.Positionals = append(page.Positionals, docsEntry{
+				Name:  docsString(pos.WriteSpecTo),
+				Usage: pos.Usage,
+			})
+		}
+	}
+
+	// the index links to all groups and commands, group pages to their children
+	var commands []string
+	if name == "" {
+		commands = append(p.Groups(), p.listedCommands()...)
+		slices.Sort(commands)
+	} else {
+		for _, child := range usage.Commands {
+			commands = append(commands, joinName(name, child))
+		}
+	}
+	for _, command := range commands {
+		page.Commands = append(page.Commands, docsEntry{
+			Name:  command,
+			Link:  meta.PageName(p.Info.Executable, command) + extension,
+			Usage: p.summary(command),
+		})
+	}
+
+	// aliases link to the page they expand to
+	for _, alias := range usage.Aliases {
+		expansion := strings.Join(append([]string{p.Info.Executable}, alias.Expansion...), " ")
+		entry := docsEntry{
+			Anchor:  "alias-" + alias.Name,
+			Name:    alias.Name,
+			Usage:   alias.Description,
+			Details: "alias for " + expansion,
+		}
+		if target := p.aliasTarget(alias.Expansion); target != "" {
+			entry.Link = meta.PageName(p.Info.Executable, target) + extension
+		}
+		page.Aliases = append(page.Aliases, entry)
+	}
+
+	return page
+}
+
+// aliasTarget returns the name of the group or command the given expansion of an alias refers to.
+// If there is no such group or command, returns the empty string.
+func (p Program[E, P, F, R]) aliasTarget(expansion []string) string {
+	if len(expansion) == 0 {
+		return ""
+	}
+	name, _, err := p.descendGroups(expansion[0], expansion[1:])
+	if err != nil {
+		return ""
+	}
+	if _, ok := p.Command(name); ok || p.hasGroup(name) {
+		return name
+	}
+	return ""
+}
+
+// docsFlag returns an entry describing flag.
+func docsFlag(flag meta.Flag) docsEntry {
+	anchor := "flag-" + flag.FieldName
+	if len(flag.Long) > 0 {
+		anchor = "flag-" + flag.Long[0]
+	} else if len(flag.Short) > 0 {
+		anchor = "flag-" + flag.Short[0]
+	}
+
+	return docsEntry{
+		Anchor:  anchor,
+		Name:    docsString(flag.WriteLongSpecTo),
+		Usage:   flag.Usage,
+		Details: flag.Details(),
+	}
+}
+
+// docsString returns the string written by write.
+func docsString(write func(w io.Writer) error) string {
+	var builder strings.Builder
+	_ = write(&builder) // writing to a builder never fails
+	return builder.String()
+}
+
+const markdownDocsTemplate = `# {{ .Title }}
+{{ with .Description }}
+{{ . }}
+{{ end }}
+## Usage
+
+` + "```" + `
+{{ .Synopsis }}
+` + "```" + `
+{{ define "entries" }}
+{{ range . -}}
+- {{ with .Anchor }}<a id="{{ . }}"></a>{{ end }}{{ if .Link }}[` + "`{{ .Name }}`" + `]({{ .Link }}){{ else }}` + "`{{ .Name }}`" + `{{ end }}{{ with .Usage }}: {{ . }}{{ end }}{{ with .Details }} ({{ . }}){{ end }}
+{{ end -}}
+{{ end -}}
+{{ with .Flags }}
+## Flags
+{{ template "entries" . }}{{ end -}}
+{{ with .Positionals }}
+## Arguments
+{{ template "entries" . }}{{ end -}}
+{{ with .GlobalFlags }}
+## Global Flags
+{{ template "entries" . }}{{ end -}}
+{{ with .Commands }}
+## Commands
+{{ template "entries" . }}{{ end -}}
+{{ with .Aliases }}
+## Aliases
+{{ template "entries" . }}{{ end -}}
+{{ with .Index }}
+Back to the [index]({{ . }}).
+{{ end -}}
+`
+
+const htmlDocsTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }}</title>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+{{ with .Description }}<p>{{ . }}</p>
+{{ end -}}
+<h2>Usage</h2>
+<pre><code>{{ .Synopsis }}</code></pre>
+{{ define "entries" -}}
+<ul>
+{{ range . -}}
+<li{{ with .Anchor }} id="{{ . }}"{{ end }}>{{ if .Link }}<a href="{{ .Link }}"><code>{{ .Name }}</code></a>{{ else }}<code>{{ .Name }}</code>{{ end }}{{ with .Usage }}: {{ . }}{{ end }}{{ with .Details }} ({{ . }}){{ end }}</li>
+{{ end -}}
+</ul>
+{{ end -}}
+{{ with .Flags }}<h2>Flags</h2>
+{{ template "entries" . }}{{ end -}}
+{{ with .Positionals }}<h2>Arguments</h2>
+{{ template "entries" . }}{{ end -}}
+{{ with .GlobalFlags }}<h2>Global Flags</h2>
+{{ template "entries" . }}{{ end -}}
+{{ with .Commands }}<h2>Commands</h2>
+{{ template "entries" . }}{{ end -}}
+{{ with .Aliases }}<h2>Aliases</h2>
+{{ template "entries" . }}{{ end -}}
+{{ with .Index }}<p>Back to the <a href="{{ . }}">index</a>.</p>
+{{ end -}}
+</body>
+</html>
+`
@@ -0,0 +1,107 @@
+//spellchecker:words goprogram
+package goprogram //nolint:testpackage // tests internal behavior
+
+//spellchecker:words errors path filepath slices strings testing
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestProgram_WriteDocs(t *testing.T) {
+	t.Parallel()
+
+	p := makeCompletionProgram()
+	p.RegisterAlias(Alias{Name: "rl", Command: "repo list", Args: []string{"-x"}, Description: "list stuff"})
+
+	tests := []struct {
+		format    string
+		wantFiles []string
+		page      string
+		contains  []string
+	}{
+		{
+			format:    DocsMarkdown,
+			wantFiles: []string{"exe-paint.md", "exe-repo-list.md", "exe-repo-show.md", "exe-repo.md", "index.md"},
+			page:      "index.md",
+			contains: []string{
+				"# exe\n\nsomething something dark side\n",
+				"exe [--help|-h] [--version|-v] [--timeout duration] [--color when] [--global-one|-a] [--global-two|-b] [--] COMMAND [ARGS...]",
+				"## Global Flags\n\n- <a id=\"flag-help\"></a>`-h, --help`: print a help message and exit\n",
+				"## Commands\n\n- [`paint`](exe-paint.md)\n- [`repo`](exe-repo.md)\n- [`repo list`](exe-repo-list.md)\n- [`repo show`](exe-repo-show.md)\n",
+				"- <a id=\"alias-rl\"></a>[`rl`](exe-repo-list.md): list stuff (alias for exe repo list -x)\n",
+			},
+		},
+		{
+			format:    DocsMarkdown,
+			wantFiles: []string{"exe-paint.md", "exe-repo-list.md", "exe-repo-show.md", "exe-repo.md", "index.md"},
+			page:      "exe-repo-list.md",
+			contains: []string{
+				"# exe repo list\n",
+				"exe [GLOBAL FLAGS] repo list [--stdout|-o message] [--stderr|-e message] [--] [Arguments ...]",
+				"## Flags\n\n- <a id=\"flag-stdout\"></a>`-o, --stdout message` (default write to stdout)\n",
+				"## Arguments\n\n- `[Arguments ...]`: arguments\n",
+				"- [`-a, --global-one`](index.md#flag-global-one)\n",
+				"Back to the [index](index.md).\n",
+			},
+		},
+		{
+			format:    DocsHTML,
+			wantFiles: []string{"exe-paint.html", "exe-repo-list.html", "exe-repo-show.html", "exe-repo.html", "index.html"},
+			page:      "exe-paint.html",
+			contains: []string{
+				"<h1>exe paint</h1>",
+				"<li id=\"flag-color\"><code>-c, --color</code> (choices: red, blue)</li>",
+				"<li><a href=\"index.html#flag-help\"><code>-h, --help</code></a>: print a help message and exit</li>",
+				"<li id=\"alias-p\"><a href=\"exe-paint.html\"><code>p</code></a> (alias for exe paint)</li>",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format+"/"+tt.page, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			if err := p.WriteDocs(dir, tt.format); err != nil {
+				t.Fatalf("Program.WriteDocs() error = %v", err)
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatalf("failed to read directory: %v", err)
+			}
+			files := make([]string, len(entries))
+			for i, entry := range entries {
+				files[i] = entry.Name()
+			}
+			if !slices.Equal(files, tt.wantFiles) {
+				t.Errorf("Program.WriteDocs() wrote %v, want %v", files, tt.wantFiles)
+			}
+
+			page, err := os.ReadFile(filepath.Join(dir, tt.page))
+			if err != nil {
+				t.Fatalf("failed to read page: %v", err)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(string(page), want) {
+					t.Errorf("Program.WriteDocs() page %q does not contain %q", tt.page, want)
+				}
+			}
+		})
+	}
+}
+
+func TestProgram_WriteDocs_unknownFormat(t *testing.T) {
+	t.Parallel()
+
+	p := makeCompletionProgram()
+
+	err := p.WriteDocs(t.TempDir(), "pdf")
+	if !errors.Is(err, errDocsUnknownFormat) {
+		t.Errorf("Program.WriteDocs() error = %v, want errDocsUnknownFormat", err)
+	}
+}
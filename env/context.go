@@ -0,0 +1,61 @@
+// Package env provides ready-made functions for setting up the environment of a program.
+//
+//spellchecker:words goprogram
+package env
+
+//spellchecker:words context signal syscall github goprogram exit
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.tkw01536.de/goprogram"
+	"go.tkw01536.de/goprogram/exit"
+)
+
+// shutdownSignals are the signals handled by NewOSContext.
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// forceExit is called to terminate the process when a second signal is received.
+var forceExit = func(code exit.ExitCode) { code.Return() }
+
+// NewOSContext is an implementation of [goprogram.Program.NewContext] that handles interrupt and termination signals.
+//
+// The first signal received cancels the returned context.
+// The cause of the cancellation is an error created by [exit.NewSignalError], using the exit code 128 plus the number of the signal.
+// A program can use the cause to shut down gracefully.
+// When a command returns an error after the signal was received, the program exits with the code of the signal.
+//
+// A second signal received before the program has finished terminates the process immediately, using the same kind of exit code.
+// Signals are no longer handled once the context has been cleaned up.
+func NewOSContext[E any, P any, F any, R goprogram.Requirement[F]](params *P, parent context.Context) (context.Context, goprogram.ContextCleanupFunc[E, P, F, R], error) {
+	ctx, cancel := context.WithCancelCause(parent)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, shutdownSignals...)
+
+	done := make(chan struct{})
+	go func() {
+		// first signal: graceful shutdown
+		select {
+		case sig := <-signals:
+			cancel(exit.NewSignalError(sig))
+		case <-done:
+			return
+		}
+
+		// second signal: forced shutdown
+		select {
+		case sig := <-signals:
+			forceExit(exit.SignalCode(sig))
+		case <-done:
+		}
+	}()
+
+	return ctx, func(*goprogram.Context[E, P, F, R]) {
+		signal.Stop(signals)
+		close(done)
+		cancel(nil)
+	}, nil
+}
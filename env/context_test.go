@@ -0,0 +1,137 @@
+//go:build unix
+
+//spellchecker:words goprogram
+package env //nolint:testpackage // tests internal behavior
+
+//spellchecker:words context errors syscall testing time github goprogram exit meta pkglib stream
+import (
+	"bytes"
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"go.tkw01536.de/goprogram"
+	"go.tkw01536.de/goprogram/exit"
+	"go.tkw01536.de/pkglib/stream"
+)
+
+type tFlags struct{}
+
+type tProgram = goprogram.Program[struct{}, struct{}, tFlags, goprogram.EmptyRequirement[tFlags]]
+type tContext = goprogram.Context[struct{}, struct{}, tFlags, goprogram.EmptyRequirement[tFlags]]
+
+// tCommand is a command that runs the given function.
+type tCommand struct {
+	name string
+	run  func(context tContext) error
+}
+
+func (t tCommand) Run(ctx tContext) error { return t.run(ctx) }
+func (t tCommand) Description() goprogram.Description[tFlags, goprogram.EmptyRequirement[tFlags]] {
+	return goprogram.Description[tFlags, goprogram.EmptyRequirement[tFlags]]{Command: t.name}
+}
+
+// sendSignal sends sig to the current process.
+func sendSignal(t *testing.T, sig syscall.Signal) {
+	t.Helper()
+
+	if err := syscall.Kill(syscall.Getpid(), sig); err != nil {
+		t.Fatalf("failed to send signal: %v", err)
+	}
+}
+
+// waitDone waits for ctx to be done, or fails the test after a timeout.
+func waitDone(t *testing.T, ctx context.Context) {
+	t.Helper()
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(10 * time.Second):
+		t.Fatal("context was not cancelled")
+	}
+}
+
+var errInterrupted = errors.New("interrupted")
+
+//nolint:paralleltest // sends signals to the test process
+func TestNewOSContext(t *testing.T) {
+	tests := []struct {
+		name     string
+		sig      syscall.Signal
+		ownError bool
+		wantCode exit.ExitCode
+	}{
+		{"interrupt", syscall.SIGINT, false, 130},
+		{"terminate", syscall.SIGTERM, false, 143},
+		{"interrupt with own error", syscall.SIGINT, true, 130},
+		{"terminate with own error", syscall.SIGTERM, true, 143},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var program tProgram
+			program.NewContext = NewOSContext
+			program.Register(tCommand{name: "wait", run: func(ctx tContext) error {
+				sendSignal(t, tt.sig)
+				waitDone(t, ctx.Context)
+
+				// the command sees the signal as cause
+				if code, _ := exit.CodeFromError(context.Cause(ctx.Context)); code != tt.wantCode {
+					t.Errorf("context.Cause() has code %v, want %v", code, tt.wantCode)
+				}
+
+				// an error returned by the command uses the code of the signal
+				if tt.ownError {
+					return errInterrupted
+				}
+
+				// further commands do not run
+				return ctx.Exec("never")
+			}})
+			program.Register(tCommand{name: "never", run: func(tContext) error {
+				t.Error("command ran after signal")
+				return nil
+			}})
+
+			var stderr bytes.Buffer
+			err := program.Main(stream.NewIOStream(nil, &stderr, nil), struct{}{}, []string{"wait"})
+			if code, _ := exit.CodeFromError(err); code != tt.wantCode {
+				t.Errorf("Program.Main() code = %v, want %v (error %v)", code, tt.wantCode, err)
+			}
+			if tt.ownError && !errors.Is(err, errInterrupted) {
+				t.Errorf("Program.Main() error = %v, want wrapping %v", err, errInterrupted)
+			}
+		})
+	}
+}
+
+//nolint:paralleltest // sends signals to the test process
+func TestNewOSContext_force(t *testing.T) {
+	exited := make(chan exit.ExitCode, 1)
+	forceExit = func(code exit.ExitCode) { exited <- code }
+	defer func() { forceExit = func(code exit.ExitCode) { code.Return() } }()
+
+	ctx, cleanup, err := NewOSContext[struct{}, struct{}, tFlags, goprogram.EmptyRequirement[tFlags]](nil, context.Background())
+	if err != nil {
+		t.Fatalf("NewOSContext() returned error %v", err)
+	}
+	defer cleanup(nil)
+
+	sendSignal(t, syscall.SIGINT)
+	waitDone(t, ctx)
+
+	if code, _ := exit.CodeFromError(context.Cause(ctx)); code != 130 {
+		t.Errorf("context.Cause() has code %v, want 130", code)
+	}
+
+	sendSignal(t, syscall.SIGTERM)
+	select {
+	case code := <-exited:
+		if code != 143 {
+			t.Errorf("forced exit with code %v, want 143", code)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("second signal did not force exit")
+	}
+}
@@ -0,0 +1,55 @@
+//spellchecker:words goprogram
+package goprogram
+
+//spellchecker:words bytes errors strings essio shellescape github pkglib stream
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"al.essio.dev/pkg/shellescape"
+	"go.tkw01536.de/pkglib/stream"
+)
+
+var errExampleFailed = errors.New("example failed")
+
+// CheckExamples runs the examples of every command registered with this program, see Description.Examples.
+// It is intended to be called from a unit test, so that examples shown on usage pages cannot become outdated.
+//
+// Each example is run using Main, passing params, and with empty standard input.
+// An example fails when Main returns an error, or when the example has an Output and the standard output differs from it.
+// Trailing newlines are ignored when comparing output.
+//
+// CheckExamples reports every failed example, combined using errors.Join.
+// When all examples succeed, it returns nil.
+func (p Program[E, P, F, R]) CheckExamples(params P) error {
+	var errs []error
+	for _, name := range p.Commands() {
+		for _, example := range p.commands[name].Description().Examples {
+			if err := p.checkExample(params, example.Args, example.Output); err != nil {
+				invocation := shellescape.QuoteCommand(append([]string{p.Info.Executable}, example.Args...))
+				errs = append(errs, fmt.Errorf("%w: command %q: `%s`: %w", errExampleFailed, name, invocation, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkExample runs the program with the given arguments and compares the standard output to want (if non-empty).
+func (p Program[E, P, F, R]) checkExample(params P, args []string, want string) error {
+	var stdout, stderr bytes.Buffer
+	str := stream.NewIOStream(&stdout, &stderr, strings.NewReader(""))
+
+	if err := p.Main(str, params, args); err != nil {
+		return fmt.Errorf("%w (standard error %q)", err, stderr.String())
+	}
+
+	if want == "" {
+		return nil
+	}
+	if got := stdout.String(); strings.TrimRight(got, "\n") != strings.TrimRight(want, "\n") {
+		return fmt.Errorf("got output %q, want %q", got, want)
+	}
+	return nil
+}
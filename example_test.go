@@ -0,0 +1,76 @@
+//spellchecker:words goprogram
+package goprogram //nolint:testpackage // tests internal behavior
+
+//spellchecker:words errors strings testing goprogram meta
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"go.tkw01536.de/goprogram/meta"
+)
+
+func TestProgram_CheckExamples(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		examples []meta.Example
+		wantErr  []string
+	}{
+		{
+			name: "no examples",
+		},
+		{
+			name: "passing examples",
+			examples: []meta.Example{
+				{Args: []string{"echo", "hello", "world"}, Output: "[hello world]"},
+				{Args: []string{"echo", "unchecked"}},
+			},
+		},
+		{
+			name: "wrong output",
+			examples: []meta.Example{
+				{Args: []string{"echo", "hello"}, Output: "[hello]\n"},
+				{Args: []string{"echo", "hello"}, Output: "[goodbye]"},
+			},
+			wantErr: []string{"command \"echo\": `exe echo hello`: got output \"[hello]\\n\", want \"[goodbye]\""},
+		},
+		{
+			name: "failing examples",
+			examples: []meta.Example{
+				{Args: []string{"echo", "--unknown"}},
+				{Args: []string{"missing"}},
+			},
+			wantErr: []string{"`exe echo --unknown`", "`exe missing`"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			echo := makeEchoCommand("echo").(*tCommand[echoStruct])
+			echo.MDesc.Examples = tt.examples
+
+			program := makeProgram()
+			program.Register(echo)
+
+			err := program.CheckExamples("")
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("Program.CheckExamples() error = %v, want nil", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, errExampleFailed) {
+				t.Fatalf("Program.CheckExamples() error = %v, want errExampleFailed", err)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Program.CheckExamples() error = %q, want containing %q", err.Error(), want)
+				}
+			}
+		})
+	}
+}
@@ -1,10 +1,12 @@
 //spellchecker:words exit
 package exit
 
-//spellchecker:words github pkglib docfmt stream
+//spellchecker:words strings github goprogram terminal pkglib docfmt stream
 import (
 	"fmt"
+	"strings"
 
+	"go.tkw01536.de/goprogram/terminal"
 	"go.tkw01536.de/pkglib/stream"
 )
 
@@ -12,7 +14,15 @@ var errUnknown = NewErrorWithCode("unknown error", ExitGeneric)
 
 // Die prints a non-nil err to io.Stderr and returns an error with an exit code.
 // If err is nil, it does nothing and returns nil.
+//
+// The prefix of the message is highlighted if standard error is a terminal, see [terminal.ColorAuto] and DieStyled.
 func Die(str stream.IOStream, err error) error {
+	return DieStyled(str, err, terminal.ColorAuto.Enabled(str.Stderr))
+}
+
+// DieStyled is like Die, but highlights the prefix of the message if and only if styled is true.
+// The prefix is the part of the message before the first colon, or the entire message if there is none.
+func DieStyled(str stream.IOStream, err error, styled bool) error {
 	// fast case: not an error
 	if err == nil {
 		return nil
@@ -25,8 +35,20 @@ func Die(str stream.IOStream, err error) error {
 
 	// print the error message to standard error in a wrapped way
 	if message := fmt.Sprint(err); message != "" {
+		if styled {
+			message = styleMessage(message)
+		}
 		_, _ = str.EPrintln(message) // no way to report the failure
 	}
 
 	return err
 }
+
+// styleMessage highlights the prefix of an error message, see DieStyled.
+func styleMessage(message string) string {
+	prefix, rest, found := strings.Cut(message, ":")
+	if !found {
+		return terminal.BoldRed.Apply(message)
+	}
+	return terminal.BoldRed.Apply(prefix) + ":" + rest
+}
@@ -0,0 +1,43 @@
+//spellchecker:words exit
+package exit_test
+
+//spellchecker:words bytes testing github goprogram exit pkglib stream
+import (
+	"bytes"
+	"testing"
+
+	"go.tkw01536.de/goprogram/exit"
+	"go.tkw01536.de/pkglib/stream"
+)
+
+func TestDieStyled(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		err    error
+		styled bool
+		want   string
+	}{
+		{"nil error", nil, true, ""},
+		{"plain error", exit.NewErrorWithCode("something: went wrong", exit.ExitGeneric), false, "something: went wrong\n"},
+		{"styled error", exit.NewErrorWithCode("something: went wrong", exit.ExitGeneric), true, "\x1b[1;31msomething\x1b[0m: went wrong\n"},
+		{"styled error without prefix", exit.NewErrorWithCode("failure", exit.ExitGeneric), true, "\x1b[1;31mfailure\x1b[0m\n"},
+		{"styled unknown error", errUnrelated, true, "\x1b[1;31munknown error\x1b[0m: unrelated\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stderr bytes.Buffer
+			err := exit.DieStyled(stream.NewIOStream(nil, &stderr, nil), tt.err, tt.styled)
+
+			if (err == nil) != (tt.err == nil) {
+				t.Errorf("DieStyled() error = %v, want error %v", err, tt.err)
+			}
+			if got := stderr.String(); got != tt.want {
+				t.Errorf("DieStyled() wrote %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
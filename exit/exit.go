@@ -45,6 +45,10 @@ const (
 	// ExitCommandArguments indicates that the user attempted to pass invalid command-specific arguments to a subcommand.
 	ExitCommandArguments ExitCode = 4
 
+	// ExitTimeout indicates that a command did not finish within its time limit.
+	// It is the same code as used by the timeout(1) utility.
+	ExitTimeout ExitCode = 124
+
 	// ExitContext indicates an error with the underlying command context.
 	ExitContext ExitCode = 254
 
@@ -0,0 +1,24 @@
+//spellchecker:words exit
+package exit
+
+//spellchecker:words syscall
+import (
+	"os"
+	"syscall"
+)
+
+// SignalCode returns the exit code conventionally used by a process terminated by sig, that is 128 plus the number of the signal.
+// When sig is not a [syscall.Signal], returns [ExitGeneric].
+func SignalCode(sig os.Signal) ExitCode {
+	number, ok := sig.(syscall.Signal)
+	if !ok {
+		return ExitGeneric
+	}
+	return Code(128 + int(number))
+}
+
+// NewSignalError creates a new error indicating that the program received sig.
+// The error holds the exit code returned by [SignalCode].
+func NewSignalError(sig os.Signal) error {
+	return NewErrorWithCode("received signal: "+sig.String(), SignalCode(sig))
+}
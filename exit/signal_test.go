@@ -0,0 +1,47 @@
+//spellchecker:words exit
+package exit_test
+
+//spellchecker:words syscall testing github goprogram exit
+import (
+	"os"
+	"syscall"
+	"testing"
+
+	"go.tkw01536.de/goprogram/exit"
+)
+
+type fakeSignal struct{}
+
+func (fakeSignal) Signal()        {}
+func (fakeSignal) String() string { return "fake" }
+
+func TestSignalCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		sig  os.Signal
+		want exit.ExitCode
+	}{
+		{"interrupt", os.Interrupt, 130},
+		{"terminate", syscall.SIGTERM, 143},
+		{"not a syscall signal", fakeSignal{}, exit.ExitGeneric},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := exit.SignalCode(tt.sig); got != tt.want {
+				t.Errorf("SignalCode() = %v, want %v", got, tt.want)
+			}
+
+			err := exit.NewSignalError(tt.sig)
+			if got, ok := exit.CodeFromError(err); !ok || got != tt.want {
+				t.Errorf("CodeFromError(NewSignalError()) = %v, %v, want %v, true", got, ok, tt.want)
+			}
+			if got, want := err.Error(), "received signal: "+tt.sig.String(); got != want {
+				t.Errorf("NewSignalError().Error() = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+//spellchecker:words goprogram
+package goprogram
+
+//spellchecker:words slices strings
+import (
+	"slices"
+	"strings"
+)
+
+// Group represents a group of commands.
+//
+// Groups allow building a tree of commands.
+// The name of a group, command or nested group consists of several words, separated by single spaces.
+// The last word is the name of the item itself, the preceding words are the name of the group it belongs to.
+// For example, the command "repo list" belongs to the group "repo".
+//
+// When a program is invoked with the name of a group, the next positional argument selects a command or group within it.
+// Aliases and keywords may target commands within groups by expanding into the name of a group, followed by further positional arguments.
+type Group struct {
+	// Name is the full name of this group
+	Name string
+
+	// Description for the usage page
+	Description string
+
+	// Summary is a one-line summary of the group, shown next to its name when commands are listed.
+	// When empty, the first sentence of Description is used instead.
+	Summary string
+
+	// Category optionally names the section the group is listed in on the main usage page, see Program.Categories.
+	// It is only used for top-level groups.
+	Category string
+}
+
+// RegisterGroup registers a new group.
+// See also Group.
+//
+// If a group or command with the same name already exists, or the parent group does not exist, RegisterGroup calls panic().
+func (p *Program[E, P, F, R]) RegisterGroup(group Group) {
+	if p.groups == nil {
+		p.groups = make(map[string]Group)
+	}
+
+	name := group.Name
+	if _, ok := p.groups[name]; ok {
+		panic("RegisterGroup(): Group already registered")
+	}
+	if _, ok := p.commands[name]; ok {
+		panic("RegisterGroup(): Command with the same name already registered")
+	}
+	if !p.hasGroup(parentName(name)) {
+		panic("RegisterGroup(): Parent group not registered")
+	}
+
+	p.groups[name] = group
+}
+
+// Groups returns the names of all registered groups.
+// Groups are returned in sorted order.
+func (p Program[E, P, F, R]) Groups() []string {
+	groups := make([]string, 0, len(p.groups))
+	for group := range p.groups {
+		groups = append(groups, group)
+	}
+	slices.Sort(groups)
+	return groups
+}
+
+// Group returns the group with the provided name and if it exists.
+func (p Program[E, P, F, R]) Group(name string) (Group, bool) {
+	group, ok := p.groups[name]
+	return group, ok
+}
+
+// hasGroup checks if a group with the given name exists.
+// The empty name refers to the top-level and always exists.
+func (p Program[E, P, F, R]) hasGroup(name string) bool {
+	if name == "" {
+		return true
+	}
+	_, ok := p.groups[name]
+	return ok
+}
+
+// children returns the names of the commands and groups directly contained in the provided group.
+// The empty name refers to the top-level.
+// Hidden commands are omitted.
+//
+// Names are returned relative to the group and in sorted order.
+func (p Program[E, P, F, R]) children(group string) []string {
+	var children []string
+	for name, command := range p.commands {
+		if parentName(name) == group && !command.Description().Hidden {
+			children = append(children, baseName(name))
+		}
+	}
+	for name := range p.groups {
+		if parentName(name) == group {
+			children = append(children, baseName(name))
+		}
+	}
+	slices.Sort(children)
+	return children
+}
+
+// parentName returns the name of the group the command or group with the given name belongs to.
+// For top-level names, returns the empty string.
+func parentName(name string) string {
+	index := strings.LastIndexByte(name, ' ')
+	if index < 0 {
+		return ""
+	}
+	return name[:index]
+}
+
+// baseName returns the last word of the given name.
+func baseName(name string) string {
+	return name[strings.LastIndexByte(name, ' ')+1:]
+}
+
+// joinName joins the name of a group and the name of a child.
+func joinName(group, child string) string {
+	if group == "" {
+		return child
+	}
+	return group + " " + child
+}
@@ -0,0 +1,217 @@
+//spellchecker:words goprogram
+package goprogram //nolint:testpackage
+
+//spellchecker:words bytes reflect testing github goprogram exit pkglib stream
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"go.tkw01536.de/goprogram/exit"
+	"go.tkw01536.de/pkglib/stream"
+)
+
+//spellchecker:words nolint testpackage
+
+// Register a group of commands for a program.
+// See the test suite for instantiated types.
+func ExampleGroup() {
+	// create a new program with a "repo" group
+	p := makeProgram()
+	p.RegisterGroup(Group{Name: "repo", Description: "manage repositories"})
+
+	// register an echo command inside the group
+	// this code is reused across the test suite, hence not shown here.
+	p.Register(makeEchoCommand("repo list"))
+
+	// register an alias that expands into the nested command
+	p.RegisterAlias(Alias{Name: "ls", Command: "repo", Args: []string{"list", "all"}})
+
+	_ = p.Main(stream.FromEnv(), "", []string{"repo", "list", "hello", "world"})
+	_ = p.Main(stream.FromEnv(), "", []string{"ls"})
+
+	// Output: [hello world]
+	// [all]
+}
+
+func TestProgram_Groups(t *testing.T) {
+	t.Parallel()
+
+	p := makeProgram()
+	p.RegisterGroup(Group{Name: "b"})
+	p.RegisterGroup(Group{Name: "a"})
+	p.RegisterGroup(Group{Name: "a c"})
+	p.Register(makeEchoCommand("a d"))
+	p.Register(makeEchoCommand("e"))
+
+	{
+		got := p.Groups()
+		want := []string{"a", "a c", "b"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Program.Groups() = %v, want = %v", got, want)
+		}
+	}
+
+	{
+		got := p.Commands()
+		want := []string{"a d", "e"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Program.Commands() = %v, want = %v", got, want)
+		}
+	}
+
+	{
+		got := p.children("")
+		want := []string{"a", "b", "e"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Program.children(\"\") = %v, want = %v", got, want)
+		}
+	}
+
+	{
+		got := p.children("a")
+		want := []string{"c", "d"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Program.children(\"a\") = %v, want = %v", got, want)
+		}
+	}
+}
+
+func TestProgram_RegisterGroup_missingParent(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Program.RegisterGroup() did not panic")
+		}
+	}()
+
+	p := makeProgram()
+	p.RegisterGroup(Group{Name: "a b"})
+}
+
+func TestProgram_Register_missingGroup(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Program.Register() did not panic")
+		}
+	}()
+
+	p := makeProgram()
+	p.Register(makeEchoCommand("a b"))
+}
+
+func TestProgram_GroupUsage_hiddenChildren(t *testing.T) {
+	t.Parallel()
+
+	p := makeProgram()
+	p.RegisterGroup(Group{Name: "repo", Description: "manage repositories"})
+
+	secret := makeEchoCommand("repo secret").(*tCommand[echoStruct])
+	secret.MDesc.Hidden = true
+	p.Register(secret)
+
+	group, _ := p.Group("repo")
+	got := p.GroupUsage(group).String()
+	want := "Usage: exe [--help|-h] [--version|-v] [--timeout duration] [--color when] [--global-one|-a] [--global-two|-b] [--] repo COMMAND [ARGS...]\n\nmanage repositories\n\n   -h, --help\n      print a help message and exit\n\n   -v, --version\n      print a version message and exit\n\n   --timeout duration\n      maximum time the command may run\n\n   --color when\n      when to use colors in output (choices: auto, always, never; default auto)\n\n   -a, --global-one\n      \n\n   -b, --global-two\n      \n\n   COMMAND [ARGS...]\n      Command to call. See individual commands for more help."
+	if got != want {
+		t.Errorf("Program.GroupUsage().String() = %q, want %q", got, want)
+	}
+}
+
+func TestProgram_Main_groups(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+
+		wantStdout string
+		wantStderr string
+		wantCode   uint8
+	}{
+		{
+			name:       "top-level command",
+			args:       []string{"top", "hello"},
+			wantStdout: "[hello]\n",
+		},
+		{
+			name:       "nested command",
+			args:       []string{"repo", "list", "hello"},
+			wantStdout: "[hello]\n",
+		},
+		{
+			name:       "deeply nested command",
+			args:       []string{"repo", "remote", "add", "hello"},
+			wantStdout: "[hello]\n",
+		},
+		{
+			name:       "alias to nested command",
+			args:       []string{"ra", "hello"},
+			wantStdout: "[origin hello]\n",
+		},
+		{
+			name:       "keyword to nested command",
+			args:       []string{"list-keyword", "hello"},
+			wantStdout: "[hello]\n",
+		},
+		{
+			name:       "group without command",
+			args:       []string{"repo"},
+			wantStderr: "missing command for repo: must be one of \"list\", \"remote\"\n",
+			wantCode:   2,
+		},
+		{
+			name:       "group with unknown command",
+			args:       []string{"repo", "remote", "delete"},
+			wantStderr: "unknown command: must be one of \"add\"\n",
+			wantCode:   2,
+		},
+		{
+			name:       "group help",
+			args:       []string{"repo", "--help"},
+			wantStdout: "Usage: exe [--help|-h] [--version|-v] [--timeout duration] [--color when] [--global-one|-a] [--global-two|-b] [--] repo COMMAND [ARGS...]\n\nmanage repositories\n\n   -h, --help\n      print a help message and exit\n\n   -v, --version\n      print a version message and exit\n\n   --timeout duration\n      maximum time the command may run\n\n   --color when\n      when to use colors in output (choices: auto, always, never; default auto)\n\n   -a, --global-one\n      \n\n   -b, --global-two\n      \n\n   COMMAND [ARGS...]\n      Command to call. One of \"list\", \"remote\". See individual commands for more help.\n",
+		},
+		{
+			name:       "main help",
+			args:       []string{"--help"},
+			wantStdout: "Usage: exe [--help|-h] [--version|-v] [--timeout duration] [--color when] [--global-one|-a] [--global-two|-b] [--] COMMAND [ARGS...]\n\nsomething something dark side\n\n   -h, --help\n      print a help message and exit\n\n   -v, --version\n      print a version message and exit\n\n   --timeout duration\n      maximum time the command may run\n\n   --color when\n      when to use colors in output (choices: auto, always, never; default auto)\n\n   -a, --global-one\n      \n\n   -b, --global-two\n      \n\n   COMMAND [ARGS...]\n      Command to call. See individual commands for more help.\n\nCommands:\n\n   repo   manage repositories\n   top\n   ra\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdoutBuffer bytes.Buffer
+			var stderrBuffer bytes.Buffer
+			stream := stream.NewIOStream(&stdoutBuffer, &stderrBuffer, nil)
+
+			program := makeProgram()
+			program.RegisterGroup(Group{Name: "repo", Description: "manage repositories"})
+			program.RegisterGroup(Group{Name: "repo remote"})
+			program.Register(makeEchoCommand("top"))
+			program.Register(makeEchoCommand("repo list"))
+			program.Register(makeEchoCommand("repo remote add"))
+			program.RegisterAlias(Alias{Name: "ra", Command: "repo remote add", Args: []string{"origin"}})
+			program.RegisterKeyword("list-keyword", func(args *iArguments, pos *[]string) error {
+				args.Command = "repo"
+				*pos = append([]string{"list"}, *pos...)
+				return nil
+			})
+
+			code, _ := exit.CodeFromError(program.Main(stream, "", tt.args))
+
+			if gotCode := uint8(code); gotCode != tt.wantCode {
+				t.Errorf("Program.Main() code = %v, wantCode %v", gotCode, tt.wantCode)
+			}
+			if gotStdout := stdoutBuffer.String(); gotStdout != tt.wantStdout {
+				t.Errorf("Program.Main() stdout = %q, wantStdout %q", gotStdout, tt.wantStdout)
+			}
+			if gotStderr := stderrBuffer.String(); gotStderr != tt.wantStderr {
+				t.Errorf("Program.Main() stderr = %q, wantStderr %q", gotStderr, tt.wantStderr)
+			}
+		})
+	}
+}
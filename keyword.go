@@ -1,6 +1,9 @@
 //spellchecker:words goprogram
 package goprogram
 
+//spellchecker:words slices
+import "slices"
+
 //spellchecker:words Positionals
 
 // Keywords are special "commands" that manipulate arguments and positionals before execution.
@@ -23,3 +26,14 @@ func (p *Program[E, P, F, R]) RegisterKeyword(name string, keyword Keyword[F]) {
 
 	p.keywords[name] = keyword
 }
+
+// Keywords returns the names of all registered keywords.
+// Keywords are returned in sorted order.
+func (p Program[E, P, F, R]) Keywords() []string {
+	keywords := make([]string, 0, len(p.keywords))
+	for keyword := range p.keywords {
+		keywords = append(keywords, keyword)
+	}
+	slices.Sort(keywords)
+	return keywords
+}
@@ -0,0 +1,71 @@
+//spellchecker:words goprogram
+package goprogram
+
+//spellchecker:words path filepath github goprogram exit meta
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"go.tkw01536.de/goprogram/exit"
+	"go.tkw01536.de/goprogram/meta"
+)
+
+var errManWrite = exit.NewErrorWithCode("unable to write man page", exit.ExitGeneric)
+
+// WriteManPage writes a man page for the command or group with the given name to w.
+// When name is empty, writes the man page for the program itself.
+//
+// Pages are written in roff format for section 1 of the manual.
+// They reference each other, see meta.PageName and meta.Meta.WriteManPageTo for details.
+//
+// The returned error is nil, or of type exit.Error.
+func (p Program[E, P, F, R]) WriteManPage(w io.Writer, name string) error {
+	usage, err := p.usage(name)
+	if err != nil {
+		return err
+	}
+
+	if err := usage.WriteManPageTo(w, p.Info); err != nil {
+		return fmt.Errorf("%w: %w", errManWrite, err)
+	}
+	return nil
+}
+
+// WriteManPages writes man pages for the program, all groups and all commands into dir.
+// Each page is written to a file named after meta.PageName with a ".1" extension.
+// Existing files are overwritten.
+//
+// It is intended to be called by packaging scripts.
+// The returned error is nil, or of type exit.Error.
+func (p Program[E, P, F, R]) WriteManPages(dir string) error {
+	names := append([]string{""}, p.Groups()...)
+	names = append(names, p.listedCommands()...)
+
+	for _, name := range names {
+		path := filepath.Join(dir, meta.PageName(p.Info.Executable, name)+".1")
+		if err := writeFile(path, errManWrite, func(w io.Writer) error {
+			return p.WriteManPage(w, name)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFile creates or truncates the file at path, and writes to it using write.
+// Any error is wrapped in errWrite.
+func writeFile(path string, errWrite error, write func(w io.Writer) error) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errWrite, err)
+	}
+	defer func() {
+		if cerr := file.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("%w: %w", errWrite, cerr)
+		}
+	}()
+
+	return write(file)
+}
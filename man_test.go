@@ -0,0 +1,68 @@
+//spellchecker:words goprogram
+package goprogram //nolint:testpackage // tests internal behavior
+
+//spellchecker:words errors path filepath slices strings testing
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestProgram_WriteManPages(t *testing.T) {
+	t.Parallel()
+
+	p := makeCompletionProgram()
+	p.RegisterAlias(Alias{Name: "rs", Command: "repo", Args: []string{"show"}})
+
+	dir := t.TempDir()
+	if err := p.WriteManPages(dir); err != nil {
+		t.Fatalf("Program.WriteManPages() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read directory: %v", err)
+	}
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+
+	wantNames := []string{"exe-paint.1", "exe-repo-list.1", "exe-repo-show.1", "exe-repo.1", "exe.1"}
+	if !slices.Equal(names, wantNames) {
+		t.Errorf("Program.WriteManPages() wrote %v, want %v", names, wantNames)
+	}
+
+	// the command page should reference the alias
+	page, err := os.ReadFile(filepath.Join(dir, "exe-paint.1"))
+	if err != nil {
+		t.Fatalf("failed to read page: %v", err)
+	}
+	if want := ".B p\nAlias for \\fBexe paint\\fR.\n"; !strings.Contains(string(page), want) {
+		t.Errorf("Program.WriteManPages() page does not contain %q", want)
+	}
+
+	// an alias to a group and arguments should be listed on the page of the command it resolves to
+	page, err = os.ReadFile(filepath.Join(dir, "exe-repo-show.1"))
+	if err != nil {
+		t.Fatalf("failed to read page: %v", err)
+	}
+	if want := ".B rs\nAlias for \\fBexe repo show\\fR.\n"; !strings.Contains(string(page), want) {
+		t.Errorf("Program.WriteManPages() page does not contain %q", want)
+	}
+}
+
+func TestProgram_WriteManPage_unknown(t *testing.T) {
+	t.Parallel()
+
+	p := makeCompletionProgram()
+
+	err := p.WriteManPage(io.Discard, "nope")
+	if !errors.Is(err, errProgramUnknownCommand) {
+		t.Errorf("Program.WriteManPage() error = %v, want errProgramUnknownCommand", err)
+	}
+}
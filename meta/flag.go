@@ -1,12 +1,14 @@
 //spellchecker:words meta
 package meta
 
-//spellchecker:words slices github pkglib docfmt text
+//spellchecker:words slices github goprogram terminal pkglib docfmt text
 import (
 	"fmt"
 	"io"
 	"slices"
+	"strings"
 
+	"go.tkw01536.de/goprogram/terminal"
 	"go.tkw01536.de/pkglib/text"
 )
 
@@ -19,26 +21,33 @@ type Flag struct {
 	//   -n, --number digit  A digit used within something (default: 42)
 
 	// The name of the underlying struct field this flag comes from.
-	FieldName string // "Number"
+	FieldName string `json:"-"` // "Number"
 
 	// Short and Long Names of the flag
 	// each potentially more than one
-	Short []string // ["n"]
-	Long  []string // ["number"]
+	Short []string `json:"short,omitempty"` // ["n"]
+	Long  []string `json:"long,omitempty"`  // ["number"]
 
 	// Indicates if the flag is required
-	Required bool // false
+	Required bool `json:"required,omitempty"` // false
+
+	// Indicates if the flag is a boolean switch, meaning it does not take a value
+	Boolean bool `json:"boolean,omitempty"` // false
 
 	// Name and Description of the flag in help texts
-	Value string // "digit"
-	Usage string // "A digit used within something"
+	Value string `json:"value,omitempty"` // "digit"
+	Usage string `json:"usage,omitempty"` // "A digit used within something"
 
 	// Default value of the flag, as shown to the user.
 	// When multiple default values are set, they are joined as a string.
-	Default string // "42"
+	Default string `json:"default,omitempty"` // "42"
 
 	// Valid choices for the option
-	Choices []string
+	Choices []string `json:"choices,omitempty"`
+
+	// Name of the environment variable the flag is bound to, if any.
+	// When the flag is not passed on the command line, its value is read from this variable before falling back to the default.
+	Env string `json:"env,omitempty"` // "EXE_NUMBER"
 }
 
 // WriteSpecTo writes a short specification of f into w.
@@ -48,7 +57,12 @@ type Flag struct {
 //
 // WriteSpecTo adds braces around the argument if it is optional.
 func (f Flag) WriteSpecTo(w io.Writer) error {
-	return f.spec(w, "|", true, true)
+	return f.writeSpecTo(w, false)
+}
+
+// writeSpecTo is like WriteSpecTo, but optionally styles the specification.
+func (f Flag) writeSpecTo(w io.Writer, styled bool) error {
+	return f.spec(w, "|", true, true, styled)
 }
 
 // WriteLongSpecTo writes a long specification of f into w.
@@ -58,15 +72,23 @@ func (f Flag) WriteSpecTo(w io.Writer) error {
 //
 // WriteLongSpecTo does not add any brackets around the argument.
 func (opt Flag) WriteLongSpecTo(w io.Writer) error {
-	return opt.spec(w, ", ", false, false)
+	return opt.spec(w, ", ", false, false, false)
 }
 
+// Styles used for the different parts of a flag.
+const (
+	flagNameStyle    = terminal.Cyan
+	flagValueStyle   = terminal.Underline
+	flagDefaultStyle = terminal.Green
+)
+
 // spec implements SpecShort and SpecLong.
 //
 // sep indicates how to separate arguments.
 // longFirst indicates that long argument names should be listed before short arguments.
 // optionalBraces indicates if braces should be placed around the argument if it is optional.
-func (opt Flag) spec(w io.Writer, sep string, longFirst bool, optionalBraces bool) (err error) {
+// styled indicates if names and value should be styled.
+func (opt Flag) spec(w io.Writer, sep string, longFirst bool, optionalBraces bool, styled bool) (err error) {
 	// if the argument is optional put braces around it!
 	if optionalBraces && !opt.Required {
 		if _, err := io.WriteString(w, "["); err != nil {
@@ -83,12 +105,12 @@ func (opt Flag) spec(w io.Writer, sep string, longFirst bool, optionalBraces boo
 	// collect long and short arguments and combine them
 	la := slices.Clone(opt.Long)
 	for k, v := range la {
-		la[k] = "--" + v
+		la[k] = flagNameStyle.ApplyIf("--"+v, styled)
 	}
 
 	sa := slices.Clone(opt.Short)
 	for k, v := range sa {
-		sa[k] = "-" + v
+		sa[k] = flagNameStyle.ApplyIf("-"+v, styled)
 	}
 
 	// write the joined versions of the arguments into the specification
@@ -109,7 +131,7 @@ func (opt Flag) spec(w io.Writer, sep string, longFirst bool, optionalBraces boo
 		if _, err := io.WriteString(w, " "); err != nil {
 			return fmt.Errorf("unable to write ' ': %w", err)
 		}
-		if _, err := io.WriteString(w, value); err != nil {
+		if _, err := io.WriteString(w, flagValueStyle.ApplyIf(value, styled)); err != nil {
 			return fmt.Errorf("unable to write value: %w", err)
 		}
 	}
@@ -127,6 +149,27 @@ const (
 	usageMsg3 = ""
 )
 
+// Details returns the choices, default and environment variable of opt.
+// It is of the form
+//
+//	choices: CHOICE1, CHOICE2; default DEFAULT; env: VARIABLE
+//
+// with parts omitted when they are not set.
+// This is the same wording WriteMessageTo uses inside parentheses.
+func (opt Flag) Details() string {
+	var details []string
+	if len(opt.Choices) > 0 {
+		details = append(details, "choices: "+strings.Join(opt.Choices, ", "))
+	}
+	if opt.Default != "" {
+		details = append(details, "default "+opt.Default)
+	}
+	if opt.Env != "" {
+		details = append(details, "env: "+opt.Env)
+	}
+	return strings.Join(details, "; ")
+}
+
 // WriteMessageTo writes a long message of f to w.
 // It is of the form
 //
@@ -134,16 +177,21 @@ const (
 //
 // and
 //
-//	DESCRIPTION (choices CHOICE1, CHOICE2. default DEFAULT)
+//	DESCRIPTION (choices: CHOICE1, CHOICE2; default DEFAULT; env: VARIABLE)
 //
 // .
 //
 // This function is implicitly tested via other tests.
 func (opt Flag) WriteMessageTo(w io.Writer) error {
+	return opt.writeMessageTo(w, false)
+}
+
+// writeMessageTo is like WriteMessageTo, but optionally styles names, value and default.
+func (opt Flag) writeMessageTo(w io.Writer, styled bool) error {
 	if _, err := io.WriteString(w, usageMsg1); err != nil {
 		return fmt.Errorf("unable to write usage text header: %w", err)
 	}
-	if err := opt.WriteLongSpecTo(w); err != nil {
+	if err := opt.spec(w, ", ", false, false, styled); err != nil {
 		return fmt.Errorf("unable to write spec: %w", err)
 	}
 	if _, err := io.WriteString(w, usageMsg2); err != nil {
@@ -160,7 +208,10 @@ func (opt Flag) WriteMessageTo(w io.Writer) error {
 		choices := opt.Choices
 		hasChoices := len(choices) > 0
 
-		if hasDefault || hasChoices {
+		env := opt.Env
+		hasEnv := env != ""
+
+		if hasDefault || hasChoices || hasEnv {
 			if _, err := io.WriteString(w, " ("); err != nil {
 				return fmt.Errorf("unable to write '(': %w", err)
 			}
@@ -171,7 +222,7 @@ func (opt Flag) WriteMessageTo(w io.Writer) error {
 				if _, err := text.Join(w, opt.Choices, ", "); err != nil {
 					return fmt.Errorf("unable to join choices: %w", err)
 				}
-				if hasDefault {
+				if hasDefault || hasEnv {
 					if _, err := io.WriteString(w, "; "); err != nil {
 						return fmt.Errorf("unable to write '; ': %w", err)
 					}
@@ -182,9 +233,23 @@ func (opt Flag) WriteMessageTo(w io.Writer) error {
 				if _, err := io.WriteString(w, "default "); err != nil {
 					return fmt.Errorf("unable to write 'default ': %w", err)
 				}
-				if _, err := io.WriteString(w, Default); err != nil {
+				if _, err := io.WriteString(w, flagDefaultStyle.ApplyIf(Default, styled)); err != nil {
 					return fmt.Errorf("unable to write default value: %w", err)
 				}
+				if hasEnv {
+					if _, err := io.WriteString(w, "; "); err != nil {
+						return fmt.Errorf("unable to write '; ': %w", err)
+					}
+				}
+			}
+
+			if hasEnv {
+				if _, err := io.WriteString(w, "env: "); err != nil {
+					return fmt.Errorf("unable to write 'env: ': %w", err)
+				}
+				if _, err := io.WriteString(w, env); err != nil {
+					return fmt.Errorf("unable to write environment variable: %w", err)
+				}
 			}
 
 			if _, err := io.WriteString(w, ")"); err != nil {
@@ -136,6 +136,16 @@ func TestFlag_WriteMessageTo(t *testing.T) {
 			meta.Flag{Usage: "this one is named", Value: "name", Short: []string{"s"}, Long: []string{"long"}, Default: "default", Choices: []string{"choice1", "choice2"}},
 			"\n\n   -s, --long name\n      this one is named (choices: choice1, choice2; default default)",
 		},
+		{
+			"named option with default and environment variable",
+			meta.Flag{Usage: "this one is named", Value: "name", Long: []string{"long"}, Default: "default", Env: "EXE_LONG"},
+			"\n\n   --long name\n      this one is named (default default; env: EXE_LONG)",
+		},
+		{
+			"option with environment variable only",
+			meta.Flag{Usage: "from the environment", Long: []string{"long"}, Env: "EXE_LONG"},
+			"\n\n   --long\n      from the environment (env: EXE_LONG)",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
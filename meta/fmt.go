@@ -38,3 +38,12 @@ func JoinCommands(commands []string) string {
 	_ = Meta{Commands: commands}.writeCommandsTo(builder) // error should never occur
 	return builder.String()
 }
+
+// PageName returns the name of the documentation page for the given command of executable.
+// When command is empty, returns the name of the page for the executable itself.
+//
+// The name consists of the executable and the words of the command, joined by dashes.
+// It is used for man pages and reference documentation.
+func PageName(executable, command string) string {
+	return strings.Join(append([]string{executable}, strings.Fields(command)...), "-")
+}
@@ -0,0 +1,241 @@
+//spellchecker:words meta
+package meta
+
+//spellchecker:words strings
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+//spellchecker:words roff positionals
+
+// manSection is the section of the manual pages are written for.
+const manSection = "1"
+
+// WriteManPageTo writes a man page for this meta into w.
+// The page is written in roff format and belongs into section 1 of the manual.
+// Info is used to populate the title line of the page.
+//
+// The page lists all flags and positionals, including choices and defaults.
+// Pages of the parent and sub-commands as well as Aliases are referenced in the SEE ALSO section.
+func (meta Meta) WriteManPageTo(w io.Writer, info Info) error {
+	// grab a builder from the pool
+	builder := builderPool.Get().(*strings.Builder)
+	builder.Reset()
+	defer builderPool.Put(builder)
+
+	if err := meta.writeManPage(builder, info); err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, builder.String()); err != nil {
+		return fmt.Errorf("unable to write man page: %w", err)
+	}
+	return nil
+}
+
+func (meta Meta) writeManPage(builder *strings.Builder, info Info) error {
+	name := PageName(meta.Executable, meta.Command)
+	isCommand := meta.IsCommand()
+
+	// title
+	date := ""
+	if !info.BuildTime.IsZero() {
+		date = info.BuildTime.Format("2006-01-02")
+	}
+	source := strings.TrimSpace(info.Executable + " " + info.BuildVersion)
+	fmt.Fprintf(builder, ".TH %s %s %s %s %s\n", manQuote(strings.ToUpper(name)), manSection, manQuote(date), manQuote(source), manQuote("User Commands"))
+
+	// name
+	builder.WriteString(".SH NAME\n")
+	builder.WriteString(manEscape(name))
+	if summary, _, _ := strings.Cut(meta.Description, "\n"); summary != "" {
+		builder.WriteString(` \- `)
+		builder.WriteString(manEscape(summary))
+	}
+	builder.WriteString("\n")
+
+	// synopsis
+	builder.WriteString(".SH SYNOPSIS\n")
+	manLine(builder, ".B", meta.Executable)
+	for _, flag := range meta.GlobalFlags {
+		if err := flag.WriteSpecTo(manWriter{builder}); err != nil {
+			return fmt.Errorf("unable to write flag spec: %w", err)
+		}
+		builder.WriteString("\n")
+	}
+	if len(meta.GlobalFlags) > 0 {
+		builder.WriteString("[\\-\\-]\n")
+	}
+	if meta.Command != "" {
+		manLine(builder, ".B", meta.Command)
+	}
+	if isCommand {
+		for _, flag := range meta.CommandFlags {
+			if err := flag.WriteSpecTo(manWriter{builder}); err != nil {
+				return fmt.Errorf("unable to write flag spec: %w", err)
+			}
+			builder.WriteString("\n")
+		}
+		if len(meta.Positionals) > 0 {
+			builder.WriteString("[\\-\\-]\n")
+		}
+		for _, pos := range meta.Positionals {
+			if err := pos.WriteSpecTo(manWriter{builder}); err != nil {
+				return fmt.Errorf("unable to write positional spec: %w", err)
+			}
+			builder.WriteString("\n")
+		}
+	} else {
+		manLine(builder, ".I", subSpec)
+	}
+
+	// description
+	if meta.Description != "" {
+		builder.WriteString(".SH DESCRIPTION\n")
+		manParagraphs(builder, meta.Description)
+	}
+
+	// options and arguments
+	if isCommand && len(meta.CommandFlags) > 0 {
+		builder.WriteString(".SH OPTIONS\n")
+		if err := manFlags(builder, meta.CommandFlags); err != nil {
+			return err
+		}
+	}
+	if len(meta.GlobalFlags) > 0 {
+		if isCommand {
+			builder.WriteString(".SH GLOBAL OPTIONS\n")
+		} else {
+			builder.WriteString(".SH OPTIONS\n")
+		}
+		if err := manFlags(builder, meta.GlobalFlags); err != nil {
+			return err
+		}
+	}
+	if isCommand && len(meta.Positionals) > 0 {
+		builder.WriteString(".SH ARGUMENTS\n")
+		for _, pos := range meta.Positionals {
+			builder.WriteString(".TP\n")
+			if err := pos.WriteSpecTo(manWriter{builder}); err != nil {
+				return fmt.Errorf("unable to write positional spec: %w", err)
+			}
+			builder.WriteString("\n")
+			manParagraphs(builder, pos.Usage)
+		}
+	}
+
+	// commands
+	if !isCommand && len(meta.Commands) > 0 {
+		builder.WriteString(".SH COMMANDS\n")
+		for _, command := range meta.Commands {
+			builder.WriteString(".TP\n")
+			manLine(builder, ".B", command)
+			fmt.Fprintf(builder, "See \\fB%s\\fR(%s).\n", manEscape(PageName(meta.Executable, meta.Command+" "+command)), manSection)
+		}
+	}
+
+	// see also
+	var pages []string
+	if meta.Command != "" {
+		words := strings.Fields(meta.Command)
+		pages = append(pages, PageName(meta.Executable, strings.Join(words[:len(words)-1], " ")))
+	}
+	if !isCommand {
+		for _, command := range meta.Commands {
+			pages = append(pages, PageName(meta.Executable, meta.Command+" "+command))
+		}
+	}
+	if len(pages) > 0 || len(meta.Aliases) > 0 {
+		builder.WriteString(".SH SEE ALSO\n")
+	}
+	for i, page := range pages {
+		fmt.Fprintf(builder, ".BR %s (%s)", manEscape(page), manSection)
+		if i < len(pages)-1 {
+			builder.WriteString(",")
+		}
+		builder.WriteString("\n")
+	}
+	if len(meta.Aliases) > 0 {
+		builder.WriteString(".SS Aliases\n")
+		for _, alias := range meta.Aliases {
+			builder.WriteString(".TP\n")
+			manLine(builder, ".B", alias.Name)
+			fmt.Fprintf(builder, "Alias for \\fB%s\\fR.\n", manEscape(strings.Join(append([]string{meta.Executable}, alias.Expansion...), " ")))
+			if alias.Description != "" {
+				manParagraphs(builder, alias.Description)
+			}
+		}
+	}
+
+	return nil
+}
+
+// manFlags writes a tagged paragraph for each of flags into builder.
+func manFlags(builder *strings.Builder, flags []Flag) error {
+	for _, flag := range flags {
+		builder.WriteString(".TP\n")
+		if err := flag.WriteLongSpecTo(manWriter{builder}); err != nil {
+			return fmt.Errorf("unable to write flag spec: %w", err)
+		}
+		builder.WriteString("\n")
+		if flag.Usage != "" {
+			manParagraphs(builder, flag.Usage)
+		}
+		if details := flag.Details(); details != "" {
+			builder.WriteString(".br\n")
+			manLine(builder, "", details)
+		}
+	}
+	return nil
+}
+
+// manParagraphs writes text into builder, starting a new paragraph at every empty line.
+func manParagraphs(builder *strings.Builder, text string) {
+	for i, paragraph := range strings.Split(strings.TrimSpace(text), "\n\n") {
+		if i > 0 {
+			builder.WriteString(".PP\n")
+		}
+		for line := range strings.SplitSeq(paragraph, "\n") {
+			manLine(builder, "", line)
+		}
+	}
+}
+
+// manLine writes a single line of text into builder, preceded by the given macro (if any).
+func manLine(builder *strings.Builder, macro string, text string) {
+	if macro != "" {
+		builder.WriteString(macro)
+		builder.WriteString(" ")
+	}
+	builder.WriteString(manEscape(text))
+	builder.WriteString("\n")
+}
+
+// manEscaper escapes characters that have a special meaning in roff.
+var manEscaper = strings.NewReplacer(`\`, `\e`, `-`, `\-`)
+
+// manEscape escapes text for use in a line of roff.
+func manEscape(text string) string {
+	text = manEscaper.Replace(text)
+	if strings.HasPrefix(text, ".") || strings.HasPrefix(text, "'") {
+		text = `\&` + text
+	}
+	return text
+}
+
+// manQuote escapes and quotes text for use as an argument to a roff macro.
+func manQuote(text string) string {
+	return `"` + strings.ReplaceAll(manEscape(text), `"`, `\(dq`) + `"`
+}
+
+// manWriter is an io.Writer that escapes everything written to it.
+type manWriter struct {
+	builder *strings.Builder
+}
+
+func (mw manWriter) Write(p []byte) (int, error) {
+	mw.builder.WriteString(manEscaper.Replace(string(p)))
+	return len(p), nil
+}
@@ -0,0 +1,84 @@
+//spellchecker:words meta
+package meta_test
+
+//spellchecker:words strings testing time github goprogram meta
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.tkw01536.de/goprogram/meta"
+)
+
+//spellchecker:words positionals
+
+func TestMeta_WriteManPageTo(t *testing.T) {
+	t.Parallel()
+
+	info := meta.Info{
+		BuildVersion: "1.2.3",
+		BuildTime:    time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC),
+		Executable:   "cmd",
+	}
+
+	global := meta.Flag{
+		Short: []string{"q"},
+		Long:  []string{"quiet"},
+		Usage: "be quiet",
+	}
+
+	tests := []struct {
+		name string
+		meta meta.Meta
+		want string
+	}{
+		{
+			"program page",
+			meta.Meta{
+				Executable:  "cmd",
+				Description: "do something interesting\n\n.and more",
+				GlobalFlags: []meta.Flag{global},
+				Commands:    []string{"a", "b"},
+				Aliases: []meta.Alias{
+					{Name: "c", Expansion: []string{"a", "--all"}, Description: "call a"},
+				},
+			},
+			".TH \"CMD\" 1 \"2000\\-01\\-02\" \"cmd 1.2.3\" \"User Commands\"\n.SH NAME\ncmd \\- do something interesting\n.SH SYNOPSIS\n.B cmd\n[\\-\\-quiet|\\-q]\n[\\-\\-]\n.I COMMAND [ARGS...]\n.SH DESCRIPTION\ndo something interesting\n.PP\n\\&.and more\n.SH OPTIONS\n.TP\n\\-q, \\-\\-quiet\nbe quiet\n.SH COMMANDS\n.TP\n.B a\nSee \\fBcmd\\-a\\fR(1).\n.TP\n.B b\nSee \\fBcmd\\-b\\fR(1).\n.SH SEE ALSO\n.BR cmd\\-a (1),\n.BR cmd\\-b (1)\n.SS Aliases\n.TP\n.B c\nAlias for \\fBcmd a \\-\\-all\\fR.\ncall a\n",
+		},
+		{
+			"command page",
+			meta.Meta{
+				Executable:  "cmd",
+				Command:     "group sub",
+				Description: "do something specific",
+				GlobalFlags: []meta.Flag{global},
+				CommandFlags: []meta.Flag{
+					{
+						Long:    []string{"color"},
+						Value:   "name",
+						Usage:   `color to use, e.g. \red`,
+						Default: "red",
+						Choices: []string{"red", "blue"},
+					},
+				},
+				Positionals: []meta.Positional{
+					{Value: "file", Usage: "files to process", Min: 1, Max: -1},
+				},
+			},
+			".TH \"CMD\\-GROUP\\-SUB\" 1 \"2000\\-01\\-02\" \"cmd 1.2.3\" \"User Commands\"\n.SH NAME\ncmd\\-group\\-sub \\- do something specific\n.SH SYNOPSIS\n.B cmd\n[\\-\\-quiet|\\-q]\n[\\-\\-]\n.B group sub\n[\\-\\-color name]\n[\\-\\-]\nfile [file ...]\n.SH DESCRIPTION\ndo something specific\n.SH OPTIONS\n.TP\n\\-\\-color name\ncolor to use, e.g. \\ered\n.br\nchoices: red, blue; default red\n.SH GLOBAL OPTIONS\n.TP\n\\-q, \\-\\-quiet\nbe quiet\n.SH ARGUMENTS\n.TP\nfile [file ...]\nfiles to process\n.SH SEE ALSO\n.BR cmd\\-group (1)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var builder strings.Builder
+			if err := tt.meta.WriteManPageTo(&builder, info); err != nil {
+				t.Fatalf("Meta.WriteManPageTo() error = %v", err)
+			}
+			if got := builder.String(); got != tt.want {
+				t.Errorf("Meta.WriteManPageTo() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,43 +1,152 @@
 //spellchecker:words meta
 package meta
 
-//spellchecker:words strconv github pkglib docfmt
+//spellchecker:words encoding json strconv strings essio shellescape github goprogram terminal pkglib docfmt
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
+
+	"al.essio.dev/pkg/shellescape"
+	"go.tkw01536.de/goprogram/terminal"
 )
 
 //spellchecker:words positionals
 
-// Meta holds meta-information about an entire program or a subcommand.
+// Meta holds meta-information about an entire program, a group of subcommands or a subcommand.
 // It is used to generate a usage page.
 type Meta struct {
 	// Name of the Executable and Current command.
 	// When Command is empty, the entire struct describes the program as a whole.
-	Executable string
-	Command    string
+	// When Command is set and Group is true, the struct describes a group of subcommands.
+	// Otherwise, it describes a single subcommand.
+	Executable string `json:"executable"`
+	Command    string `json:"command,omitempty"`
+	Group      bool   `json:"group,omitempty"`
 
 	// Description holds a human-readable description of the object being described.
-	Description string
+	Description string `json:"description,omitempty"`
 
 	// Applicable Global, Command and Positional Flags.
-	GlobalFlags  []Flag
-	CommandFlags []Flag
-	Positionals  []Positional
+	GlobalFlags  []Flag       `json:"globalFlags,omitempty"`
+	CommandFlags []Flag       `json:"commandFlags,omitempty"`
+	Positionals  []Positional `json:"positionals,omitempty"`
+
+	// List of available sub-commands, only set when Command == "" or when describing a group.
+	Commands []string `json:"commands,omitempty"`
+
+	// Summaries optionally holds one-line summaries of the available sub-commands, indexed by name.
+	// When non-empty, the usage page lists Commands in a table along with their summaries.
+	Summaries map[string]string `json:"summaries,omitempty"`
+
+	// Categories optionally sorts the available sub-commands into named sections.
+	// When non-empty, the usage page lists one section per category (in order) instead of the list of Commands,
+	// followed by a section listing Aliases along with their expansions.
+	Categories []Category `json:"categories,omitempty"`
+
+	// Aliases referring to the object being described.
+	// They are only included in the usage page when Categories is non-empty, see also WriteManPageTo.
+	Aliases []Alias `json:"aliases,omitempty"`
+
+	// Examples of invoking the command being described.
+	Examples []Example `json:"examples,omitempty"`
+
+	// Width optionally holds the number of columns the usage page is wrapped to.
+	// Long lines are broken at spaces, with continuation lines indented to line up with the text they continue.
+	// When zero or negative, lines are never wrapped.
+	Width int `json:"-"`
+
+	// Styled indicates if the usage page should be styled for display on a terminal.
+	// Headings, flag names, values and defaults are then highlighted using ANSI escape sequences.
+	Styled bool `json:"-"`
+}
+
+// Example holds an example invocation of a command.
+type Example struct {
+	// Args holds the arguments passed to the program, excluding the executable.
+	Args []string `json:"args"`
+
+	// Description explains what the example does.
+	Description string `json:"description,omitempty"`
 
-	// List of available sub-commands, only set when Command == "".
-	Commands []string
+	// Output optionally holds the expected standard output of the example.
+	// It is not included in the usage page, but may be used to verify the example.
+	Output string `json:"output,omitempty"`
+}
+
+// Category is a named section of sub-commands on a usage page.
+type Category struct {
+	Name     string   `json:"name"`
+	Commands []string `json:"commands"`
+}
+
+// Alias holds meta-information about an alias referring to a program, group or command.
+type Alias struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	// Expansion holds the words the alias expands to, excluding the executable.
+	Expansion []string `json:"expansion"`
 }
 
 // WriteMessageTo writes the human-readable message of this meta into w.
+// When Width is positive, the message is wrapped accordingly.
 func (meta Meta) WriteMessageTo(w io.Writer) error {
-	if meta.Command != "" {
-		return meta.writeCommandMessageTo(w)
+	if meta.IsCommand() {
+		return meta.writeWrappedTo(w, meta.writeCommandMessageTo)
+	}
+
+	if err := meta.writeWrappedTo(w, meta.writeProgramMessageTo); err != nil {
+		return err
+	}
+
+	// sections wrap their rows themselves, and must not be wrapped again.
+	return meta.writeCategoriesTo(w)
+}
+
+// writeWrappedTo writes the text written by write to w, wrapping it when Width is positive.
+func (meta Meta) writeWrappedTo(w io.Writer, write func(w io.Writer) error) error {
+	if meta.Width <= 0 {
+		return write(w)
+	}
+
+	var builder strings.Builder
+	if err := write(&builder); err != nil {
+		return err
 	}
-	return meta.writeProgramMessageTo(w)
+	if _, err := io.WriteString(w, wrap(builder.String(), meta.Width)); err != nil {
+		return fmt.Errorf("unable to write message: %w", err)
+	}
+	return nil
+}
+
+// IsCommand reports if this meta describes a single subcommand, as opposed to the program or a group.
+func (meta Meta) IsCommand() bool {
+	return meta.Command != "" && !meta.Group
+}
+
+// WriteJSONTo writes a machine-readable representation of this meta into w.
+//
+// The representation is a JSON object, using the field names given by the struct tags of Meta, Flag, Positional and Alias.
+// These names are stable and intended to be consumed by other tools.
+// Empty fields are omitted.
+func (meta Meta) WriteJSONTo(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(meta); err != nil {
+		return fmt.Errorf("unable to encode json: %w", err)
+	}
+	return nil
 }
 
+// headingStyle is the style used for headings on usage pages.
+const headingStyle = terminal.Bold
+
+// usageHeading is the heading of the usage line.
+const usageHeading = "Usage:"
+
 // subSpec is spec for a subcommand.
 const subSpec = "COMMAND [ARGS...]"
 
@@ -47,6 +156,15 @@ const (
 	// subMsgTpl = subMsg1 + "%s" + subMsg2.
 	subMsg1 = "Command to call. One of "
 	subMsg2 = ". See individual commands for more help."
+
+	// subMsgCategories is the usage message of a subcommand when commands are listed by category, or there are none to list.
+	subMsgCategories = "Command to call. See individual commands for more help."
+)
+
+// names of sections listing commands and aliases.
+const (
+	commandsCategory = "Commands"
+	aliasesCategory  = "Aliases"
 )
 
 func (meta Meta) writeProgramMessageTo(w io.Writer) error {
@@ -55,7 +173,7 @@ func (meta Meta) writeProgramMessageTo(w io.Writer) error {
 	//
 
 	// main command
-	if _, err := io.WriteString(w, "Usage: "); err != nil {
+	if _, err := io.WriteString(w, headingStyle.ApplyIf(usageHeading, meta.Styled)+" "); err != nil {
 		return fmt.Errorf("unable to write 'Usage :': %w", err)
 	}
 	if _, err := io.WriteString(w, meta.Executable); err != nil {
@@ -66,7 +184,7 @@ func (meta Meta) writeProgramMessageTo(w io.Writer) error {
 		if _, err := io.WriteString(w, " "); err != nil {
 			return fmt.Errorf("unable to write ' ': %w", err)
 		}
-		if err := arg.WriteSpecTo(w); err != nil {
+		if err := arg.writeSpecTo(w, meta.Styled); err != nil {
 			return fmt.Errorf("unable to write spec: %w", err)
 		}
 	}
@@ -74,6 +192,14 @@ func (meta Meta) writeProgramMessageTo(w io.Writer) error {
 	if _, err := io.WriteString(w, " [--] "); err != nil {
 		return fmt.Errorf("unable to write ' [--] ': %w", err)
 	}
+	if meta.Command != "" {
+		if _, err := io.WriteString(w, meta.Command); err != nil {
+			return fmt.Errorf("unable to write group: %w", err)
+		}
+		if _, err := io.WriteString(w, " "); err != nil {
+			return fmt.Errorf("unable to write ' ': %w", err)
+		}
+	}
 	if _, err := io.WriteString(w, subSpec); err != nil {
 		return fmt.Errorf("unable to write sub spec: %w", err)
 	}
@@ -93,7 +219,7 @@ func (meta Meta) writeProgramMessageTo(w io.Writer) error {
 	//
 
 	for _, arg := range meta.GlobalFlags {
-		if err := arg.WriteMessageTo(w); err != nil {
+		if err := arg.writeMessageTo(w, meta.Styled); err != nil {
 			return err
 		}
 	}
@@ -110,15 +236,21 @@ func (meta Meta) writeProgramMessageTo(w io.Writer) error {
 		return fmt.Errorf("unable to write usage message: %w", err)
 	}
 
-	// replace the list of commands in subMsgTpl
-	if _, err := io.WriteString(w, subMsg1); err != nil {
-		return fmt.Errorf("unable to write sub specification: %w", err)
-	}
-	if err := meta.writeCommandsTo(w); err != nil {
-		return fmt.Errorf("unable to write commands: %w", err)
-	}
-	if _, err := io.WriteString(w, subMsg2); err != nil {
-		return fmt.Errorf("unable to sub specification: %w", err)
+	if len(meta.Categories) > 0 || len(meta.Summaries) > 0 || len(meta.Commands) == 0 {
+		if _, err := io.WriteString(w, subMsgCategories); err != nil {
+			return fmt.Errorf("unable to write sub specification: %w", err)
+		}
+	} else {
+		// replace the list of commands in subMsgTpl
+		if _, err := io.WriteString(w, subMsg1); err != nil {
+			return fmt.Errorf("unable to write sub specification: %w", err)
+		}
+		if err := meta.writeCommandsTo(w); err != nil {
+			return fmt.Errorf("unable to write commands: %w", err)
+		}
+		if _, err := io.WriteString(w, subMsg2); err != nil {
+			return fmt.Errorf("unable to sub specification: %w", err)
+		}
 	}
 
 	if _, err := io.WriteString(w, usageMsg3); err != nil {
@@ -128,6 +260,69 @@ func (meta Meta) writeProgramMessageTo(w io.Writer) error {
 	return nil
 }
 
+// writeCategoriesTo writes one section for each category to w, followed by a section listing aliases.
+// Commands are listed along with their summaries.
+//
+// When there are no categories, but summaries, a single section listing all commands is written.
+// Otherwise, nothing is written.
+func (meta Meta) writeCategoriesTo(w io.Writer) error {
+	categories := meta.Categories
+	if len(categories) == 0 {
+		if len(meta.Summaries) == 0 {
+			return nil
+		}
+		categories = []Category{{Name: commandsCategory, Commands: meta.Commands}}
+	}
+
+	for _, category := range categories {
+		rows := make([][2]string, len(category.Commands))
+		for i, command := range category.Commands {
+			rows[i] = [2]string{command, meta.Summaries[command]}
+		}
+		if err := meta.writeSectionTo(w, category.Name, rows); err != nil {
+			return err
+		}
+	}
+
+	if len(meta.Categories) == 0 || len(meta.Aliases) == 0 {
+		return nil
+	}
+	rows := make([][2]string, len(meta.Aliases))
+	for i, alias := range meta.Aliases {
+		rows[i] = [2]string{alias.Name, shellescape.QuoteCommand(alias.Expansion)}
+	}
+	return meta.writeSectionTo(w, aliasesCategory, rows)
+}
+
+// writeSectionTo writes a section with the given heading to w.
+// Each row consists of a name and an (optional) text, the texts of all rows are aligned.
+// When meta.Width is positive, texts are wrapped to fit within it, continuation lines being aligned with the texts.
+func (meta Meta) writeSectionTo(w io.Writer, heading string, rows [][2]string) error {
+	if _, err := fmt.Fprintf(w, "\n\n%s\n", headingStyle.ApplyIf(heading+":", meta.Styled)); err != nil {
+		return fmt.Errorf("unable to write section heading: %w", err)
+	}
+
+	nameWidth := 0
+	for _, row := range rows {
+		nameWidth = max(nameWidth, len(row[0]))
+	}
+	column := 3 + nameWidth + 3
+	for _, row := range rows {
+		line := "\n   " + row[0]
+		if row[1] != "" {
+			text := row[1]
+			if meta.Width > 0 {
+				text = strings.Join(wrapWords(text, meta.Width-column, meta.Width-column), "\n"+strings.Repeat(" ", column))
+			}
+			line += strings.Repeat(" ", nameWidth-len(row[0])+3) + text
+		}
+		if _, err := io.WriteString(w, line); err != nil {
+			return fmt.Errorf("unable to write section row: %w", err)
+		}
+	}
+	return nil
+}
+
 // WriteCommandsTo writes the list of commands to w.
 func (meta Meta) writeCommandsTo(w io.Writer) error {
 	if len(meta.Commands) == 0 {
@@ -153,7 +348,7 @@ func (page Meta) writeCommandMessageTo(w io.Writer) error {
 	//
 
 	// main command
-	if _, err := io.WriteString(w, "Usage: "); err != nil {
+	if _, err := io.WriteString(w, headingStyle.ApplyIf(usageHeading, page.Styled)+" "); err != nil {
 		return fmt.Errorf("unable to write 'Usage :': %w", err)
 	}
 	if _, err := io.WriteString(w, page.Executable); err != nil {
@@ -164,7 +359,7 @@ func (page Meta) writeCommandMessageTo(w io.Writer) error {
 		if _, err := io.WriteString(w, " "); err != nil {
 			return fmt.Errorf("unable to write ' ': %w", err)
 		}
-		if err := arg.WriteSpecTo(w); err != nil {
+		if err := arg.writeSpecTo(w, page.Styled); err != nil {
 			return fmt.Errorf("unable to write argument spec: %w", err)
 		}
 	}
@@ -187,7 +382,7 @@ func (page Meta) writeCommandMessageTo(w io.Writer) error {
 		if _, err := io.WriteString(w, " "); err != nil {
 			return fmt.Errorf("unable to write ' ': %w", err)
 		}
-		if err := arg.WriteSpecTo(w); err != nil {
+		if err := arg.writeSpecTo(w, page.Styled); err != nil {
 			return fmt.Errorf("unable to write flag spec: %w", err)
 		}
 	}
@@ -221,26 +416,26 @@ func (page Meta) writeCommandMessageTo(w io.Writer) error {
 	// Argument description
 	//
 
-	if _, err := io.WriteString(w, "\n\nGlobal Arguments:"); err != nil {
+	if _, err := io.WriteString(w, "\n\n"+headingStyle.ApplyIf("Global Arguments:", page.Styled)); err != nil {
 		return fmt.Errorf("unable to write 'Global Arguments': %w", err)
 	}
 	for _, opt := range page.GlobalFlags {
-		if err := opt.WriteMessageTo(w); err != nil {
+		if err := opt.writeMessageTo(w, page.Styled); err != nil {
 			return fmt.Errorf("unable to write global flags: %w", err)
 		}
 	}
 
 	// no command arguments provided!
 	if len(page.CommandFlags) == 0 && len(page.Positionals) == 0 {
-		return nil
+		return page.writeExamplesTo(w)
 	}
 
-	if _, err := io.WriteString(w, "\n\nCommand Arguments:"); err != nil {
+	if _, err := io.WriteString(w, "\n\n"+headingStyle.ApplyIf("Command Arguments:", page.Styled)); err != nil {
 		return fmt.Errorf("unable to write 'Command Arguments': %w", err)
 	}
 
 	for _, opt := range page.CommandFlags {
-		if err := opt.WriteMessageTo(w); err != nil {
+		if err := opt.writeMessageTo(w, page.Styled); err != nil {
 			return fmt.Errorf("unable to write command flags: %w", err)
 		}
 	}
@@ -262,5 +457,37 @@ func (page Meta) writeCommandMessageTo(w io.Writer) error {
 			return fmt.Errorf("unable to write positional usage message: %w", err)
 		}
 	}
+	return page.writeExamplesTo(w)
+}
+
+// writeExamplesTo writes a section listing the examples of page to w.
+// When there are no examples, nothing is written.
+func (page Meta) writeExamplesTo(w io.Writer) error {
+	if len(page.Examples) == 0 {
+		return nil
+	}
+
+	if _, err := io.WriteString(w, "\n\n"+headingStyle.ApplyIf("Examples:", page.Styled)); err != nil {
+		return fmt.Errorf("unable to write 'Examples': %w", err)
+	}
+	for _, example := range page.Examples {
+		if _, err := io.WriteString(w, usageMsg1); err != nil {
+			return fmt.Errorf("unable to write example usage message: %w", err)
+		}
+		if _, err := io.WriteString(w, shellescape.QuoteCommand(append([]string{page.Executable}, example.Args...))); err != nil {
+			return fmt.Errorf("unable to write example: %w", err)
+		}
+		if example.Description != "" {
+			if _, err := io.WriteString(w, usageMsg2); err != nil {
+				return fmt.Errorf("unable to write example usage message: %w", err)
+			}
+			if _, err := io.WriteString(w, example.Description); err != nil {
+				return fmt.Errorf("unable to write example description: %w", err)
+			}
+		}
+		if _, err := io.WriteString(w, usageMsg3); err != nil {
+			return fmt.Errorf("unable to write example usage message: %w", err)
+		}
+	}
 	return nil
 }
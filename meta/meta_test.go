@@ -49,6 +49,80 @@ func TestUsage_WriteMessageTo(t *testing.T) {
 			},
 			"Usage: cmd --global|-g name [--quiet|-q] [--] COMMAND [ARGS...]\n\ndo something interesting\n\n   -g, --global name\n      a global argument\n\n   -q, --quiet\n      be quiet (default false)\n\n   COMMAND [ARGS...]\n      Command to call. One of \"a\", \"b\", \"c\". See individual commands for more help.",
 		},
+		{
+			"main executable page with categories",
+			meta.Meta{
+				Executable:  "cmd",
+				Description: "do something interesting",
+
+				GlobalFlags: []meta.Flag{
+					{
+						Short: []string{"q"},
+						Long:  []string{"quiet"},
+						Usage: "be quiet",
+					},
+				},
+				Commands: []string{"clone", "fetch", "gc", "f"},
+				Categories: []meta.Category{
+					{Name: "Repository", Commands: []string{"clone", "fetch"}},
+					{Name: "Maintenance", Commands: []string{"gc"}},
+				},
+				Aliases: []meta.Alias{
+					{Name: "f", Expansion: []string{"fetch", "--all"}},
+					{Name: "hi", Expansion: []string{"echo", "hello world"}},
+				},
+			},
+			"Usage: cmd [--quiet|-q] [--] COMMAND [ARGS...]\n\ndo something interesting\n\n   -q, --quiet\n      be quiet\n\n   COMMAND [ARGS...]\n      Command to call. See individual commands for more help.\n\nRepository:\n\n   clone\n   fetch\n\nMaintenance:\n\n   gc\n\nAliases:\n\n   f    fetch --all\n   hi   echo 'hello world'",
+		},
+		{
+			"main executable page with summaries",
+			meta.Meta{
+				Executable: "cmd",
+				Commands:   []string{"clone", "fetch", "f"},
+				Summaries: map[string]string{
+					"clone": "clone a repository",
+					"f":     "fetch everything",
+				},
+			},
+			"Usage: cmd [--] COMMAND [ARGS...]\n\n   COMMAND [ARGS...]\n      Command to call. See individual commands for more help.\n\nCommands:\n\n   clone   clone a repository\n   fetch\n   f       fetch everything",
+		},
+		{
+			"group page",
+			meta.Meta{
+				Executable:  "cmd",
+				Command:     "group",
+				Group:       true,
+				Description: "do something grouped",
+				GlobalFlags: []meta.Flag{
+					{
+						Required: false,
+						Short:    []string{"q"},
+						Long:     []string{"quiet"},
+						Usage:    "be quiet",
+						Default:  "false",
+					},
+				},
+				Commands: []string{"a", "b"},
+			},
+			"Usage: cmd [--quiet|-q] [--] group COMMAND [ARGS...]\n\ndo something grouped\n\n   -q, --quiet\n      be quiet (default false)\n\n   COMMAND [ARGS...]\n      Command to call. One of \"a\", \"b\". See individual commands for more help.",
+		},
+		{
+			"group page without listed commands",
+			meta.Meta{
+				Executable:  "cmd",
+				Command:     "group",
+				Group:       true,
+				Description: "do something grouped",
+				GlobalFlags: []meta.Flag{
+					{
+						Short: []string{"q"},
+						Long:  []string{"quiet"},
+						Usage: "be quiet",
+					},
+				},
+			},
+			"Usage: cmd [--quiet|-q] [--] group COMMAND [ARGS...]\n\ndo something grouped\n\n   -q, --quiet\n      be quiet\n\n   COMMAND [ARGS...]\n      Command to call. See individual commands for more help.",
+		},
 		{
 			"sub executable page",
 			meta.Meta{
@@ -107,6 +181,94 @@ func TestUsage_WriteMessageTo(t *testing.T) {
 			},
 			"Usage: cmd --global|-g name [--quiet|-q] [--] sub --dud|-d dud [--silent|-s] [--] op [op ...]\n\ndo something local\n\nGlobal Arguments:\n\n   -g, --global name\n      a global argument\n\n   -q, --quiet\n      be quiet (default false)\n\nCommand Arguments:\n\n   -d, --dud dud\n      a local argument\n\n   -s, --silent\n      be silent (default true)\n\n   op [op ...]\n      operations to make",
 		},
+		{
+			"command page with examples",
+			meta.Meta{
+				Executable:  "cmd",
+				Command:     "sub",
+				Description: "do something local",
+
+				GlobalFlags: []meta.Flag{
+					{
+						Short: []string{"q"},
+						Long:  []string{"quiet"},
+						Usage: "be quiet",
+					},
+				},
+
+				Examples: []meta.Example{
+					{
+						Args:        []string{"sub", "hello world"},
+						Description: "greet the world",
+						Output:      "not rendered",
+					},
+					{
+						Args: []string{"sub"},
+					},
+				},
+			},
+			"Usage: cmd [--quiet|-q] [--] sub\n\ndo something local\n\nGlobal Arguments:\n\n   -q, --quiet\n      be quiet\n\nExamples:\n\n   cmd sub 'hello world'\n      greet the world\n\n   cmd sub",
+		},
+		{
+			"styled command page",
+			meta.Meta{
+				Executable:  "cmd",
+				Command:     "sub",
+				Description: "do something",
+
+				GlobalFlags: []meta.Flag{
+					{
+						Short: []string{"q"},
+						Long:  []string{"quiet"},
+						Usage: "be quiet",
+					},
+				},
+				CommandFlags: []meta.Flag{
+					{
+						Long:    []string{"config"},
+						Value:   "file",
+						Usage:   "configuration file",
+						Default: "cfg.toml",
+					},
+				},
+				Examples: []meta.Example{
+					{
+						Args:        []string{"sub"},
+						Description: "run it",
+					},
+				},
+
+				Styled: true,
+			},
+			"\x1b[1mUsage:\x1b[0m cmd [\x1b[36m--quiet\x1b[0m|\x1b[36m-q\x1b[0m] [--] sub [\x1b[36m--config\x1b[0m \x1b[4mfile\x1b[0m]\n\ndo something\n\n\x1b[1mGlobal Arguments:\x1b[0m\n\n   \x1b[36m-q\x1b[0m, \x1b[36m--quiet\x1b[0m\n      be quiet\n\n\x1b[1mCommand Arguments:\x1b[0m\n\n   \x1b[36m--config\x1b[0m \x1b[4mfile\x1b[0m\n      configuration file (default \x1b[32mcfg.toml\x1b[0m)\n\n\x1b[1mExamples:\x1b[0m\n\n   cmd sub\n      run it",
+		},
+		{
+			"wrapped main executable page",
+			meta.Meta{
+				Executable:  "cmd",
+				Description: "do something interesting, as explained in this long description.\n\nSecond paragraph.",
+
+				GlobalFlags: []meta.Flag{
+					{
+						Short: []string{"q"},
+						Long:  []string{"quiet"},
+						Usage: "be very quiet and do not print anything at all",
+					},
+					{
+						Long:  []string{"config"},
+						Value: "file",
+					},
+				},
+				Commands: []string{"a", "bb"},
+				Summaries: map[string]string{
+					"a":  "the first command, which does many interesting things",
+					"bb": "https://example.com/some/very/long/path and more",
+				},
+
+				Width: 40,
+			},
+			"Usage: cmd [--quiet|-q] [--config file]\n       [--] COMMAND [ARGS...]\n\ndo something interesting, as explained\nin this long description.\n\nSecond paragraph.\n\n   -q, --quiet\n      be very quiet and do not print\n      anything at all\n\n   --config file\n      \n\n   COMMAND [ARGS...]\n      Command to call. See individual\n      commands for more help.\n\nCommands:\n\n   a    the first command, which does\n        many interesting things\n   bb   https://example.com/some/very/long/path\n        and more",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -122,3 +284,102 @@ func TestUsage_WriteMessageTo(t *testing.T) {
 		})
 	}
 }
+
+func TestPageName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		executable string
+		command    string
+		want       string
+	}{
+		{"cmd", "", "cmd"},
+		{"cmd", "sub", "cmd-sub"},
+		{"cmd", "group sub", "cmd-group-sub"},
+	}
+	for _, tt := range tests {
+		if got := meta.PageName(tt.executable, tt.command); got != tt.want {
+			t.Errorf("PageName(%q, %q) = %q, want %q", tt.executable, tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestMeta_WriteJSONTo(t *testing.T) {
+	t.Parallel()
+
+	m := meta.Meta{
+		Executable:  "cmd",
+		Command:     "sub",
+		Description: "do something",
+		GlobalFlags: []meta.Flag{
+			{FieldName: "Quiet", Short: []string{"q"}, Long: []string{"quiet"}, Boolean: true, Usage: "be quiet"},
+		},
+		CommandFlags: []meta.Flag{
+			{FieldName: "Color", Long: []string{"color"}, Required: true, Value: "name", Default: "red", Choices: []string{"red", "blue"}},
+		},
+		Positionals: []meta.Positional{
+			{Value: "file", Min: 0, Max: -1},
+		},
+		Aliases: []meta.Alias{
+			{Name: "s", Expansion: []string{"sub", "--color", "blue"}},
+		},
+	}
+
+	want := `{
+  "executable": "cmd",
+  "command": "sub",
+  "description": "do something",
+  "globalFlags": [
+    {
+      "short": [
+        "q"
+      ],
+      "long": [
+        "quiet"
+      ],
+      "boolean": true,
+      "usage": "be quiet"
+    }
+  ],
+  "commandFlags": [
+    {
+      "long": [
+        "color"
+      ],
+      "required": true,
+      "value": "name",
+      "default": "red",
+      "choices": [
+        "red",
+        "blue"
+      ]
+    }
+  ],
+  "positionals": [
+    {
+      "value": "file",
+      "min": 0,
+      "max": -1
+    }
+  ],
+  "aliases": [
+    {
+      "name": "s",
+      "expansion": [
+        "sub",
+        "--color",
+        "blue"
+      ]
+    }
+  ]
+}
+`
+
+	var builder strings.Builder
+	if err := m.WriteJSONTo(&builder); err != nil {
+		t.Fatalf("Meta.WriteJSONTo() error = %v", err)
+	}
+	if got := builder.String(); got != want {
+		t.Errorf("Meta.WriteJSONTo() = %s, want %s", got, want)
+	}
+}
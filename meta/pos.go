@@ -13,13 +13,14 @@ import (
 // Positional holds meta-information about a positional argument.
 type Positional struct {
 	// Name and Description of the positional in help texts
-	Value string // defaults to "ARGUMENT"
-	Usage string
+	Value string `json:"value,omitempty"` // defaults to "ARGUMENT"
+	Usage string `json:"usage,omitempty"`
 
 	// Min and Max indicate how many positional arguments are expected for this command.
 	// Min must be >= 0. Max must be either Min, or -1.
 	// Max == -1 indicates an unlimited number of repeats.
-	Min, Max int
+	Min int `json:"min"`
+	Max int `json:"max"`
 }
 
 // ValidRange checks if positional has valid min and max values.
@@ -0,0 +1,111 @@
+//spellchecker:words meta
+package meta
+
+//spellchecker:words strings unicode github goprogram terminal
+import (
+	"strings"
+	"unicode/utf8"
+
+	"go.tkw01536.de/goprogram/terminal"
+)
+
+// usagePrefix is the prefix of the usage line of a usage page.
+const usagePrefix = usageHeading + " "
+
+// minWrapWidth is the minimal width of text that is wrapped.
+// When less space is available, text is not wrapped at all.
+const minWrapWidth = 20
+
+// wrap wraps every line of text that is longer than width.
+// Styles do not count towards the length of a line.
+//
+// Continuation lines are indented like the line they continue.
+// Lines starting with the usage prefix instead use a hanging indent aligned with the executable.
+// Empty lines, and hence paragraph breaks, are preserved.
+func wrap(text string, width int) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if visibleLength(line) <= width {
+			continue
+		}
+
+		content := strings.TrimLeft(line, " ")
+		indent := len(line) - len(content)
+
+		hang := indent
+		if strings.HasPrefix(terminal.Strip(content), usagePrefix) {
+			hang += len(usagePrefix)
+		}
+
+		lines[i] = line[:indent] + strings.Join(wrapWords(content, width-indent, width-hang), "\n"+strings.Repeat(" ", hang))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrapWords greedily splits text into lines, the first of which is at most first runes long and all others at most rest.
+// Words longer than the available width are never broken.
+// When there is not enough room for wrapping, text is returned as a single line.
+func wrapWords(text string, first, rest int) []string {
+	if min(first, rest) < minWrapWidth {
+		return []string{text}
+	}
+
+	var lines []string
+
+	var line strings.Builder
+	width, limit := 0, first
+	for _, word := range splitWords(text) {
+		length := visibleLength(word)
+		if width > 0 && width+1+length > limit {
+			lines = append(lines, line.String())
+			line.Reset()
+			width, limit = 0, rest
+		}
+		if width > 0 {
+			line.WriteByte(' ')
+			width++
+		}
+		line.WriteString(word)
+		width += length
+	}
+	return append(lines, line.String())
+}
+
+// visibleLength returns the number of runes in text, not counting styles.
+func visibleLength(text string) int {
+	return utf8.RuneCountInString(terminal.Strip(text))
+}
+
+// splitWords splits text into words separated by spaces.
+// Spaces within square brackets do not separate words, so that optional arguments in a usage line are kept together.
+// If the brackets in text are not balanced, any space separates words.
+func splitWords(text string) []string {
+	var words []string
+
+	depth, start := 0, 0
+	for i := 0; i < len(text); i++ {
+		switch {
+		case text[i] == '\x1b':
+			// skip over the escape sequence of a style
+			if end := strings.IndexByte(text[i:], 'm'); end >= 0 {
+				i += end
+			}
+		case text[i] == '[':
+			depth++
+		case text[i] == ']' && depth > 0:
+			depth--
+		case text[i] == ' ' && depth == 0:
+			if i > start {
+				words = append(words, text[start:i])
+			}
+			start = i + 1
+		}
+	}
+	if depth != 0 {
+		return strings.Fields(text)
+	}
+	if start < len(text) {
+		words = append(words, text[start:])
+	}
+	return words
+}
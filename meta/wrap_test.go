@@ -0,0 +1,101 @@
+//spellchecker:words meta
+package meta
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_wrap(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{
+			name:  "short lines are unchanged",
+			text:  "some   short   text\n\n   indented",
+			width: 30,
+			want:  "some   short   text\n\n   indented",
+		},
+		{
+			name:  "long line",
+			text:  "the quick brown fox jumps over the lazy dog",
+			width: 20,
+			want:  "the quick brown fox\njumps over the lazy\ndog",
+		},
+		{
+			name:  "indented line",
+			text:  "      the quick brown fox jumps over the lazy dog",
+			width: 30,
+			want:  "      the quick brown fox\n      jumps over the lazy dog",
+		},
+		{
+			name:  "paragraphs",
+			text:  "the quick brown fox jumps over the lazy dog\n\nthe lazy dog sleeps",
+			width: 25,
+			want:  "the quick brown fox jumps\nover the lazy dog\n\nthe lazy dog sleeps",
+		},
+		{
+			name:  "usage line",
+			text:  "Usage: exe [--help|-h] [--version|-v] [--timeout duration] [--] COMMAND [ARGS...]",
+			width: 40,
+			want:  "Usage: exe [--help|-h] [--version|-v]\n       [--timeout duration] [--] COMMAND\n       [ARGS...]",
+		},
+		{
+			name:  "styled usage line",
+			text:  "\x1b[1mUsage:\x1b[0m cmd [\x1b[36m--quiet\x1b[0m|\x1b[36m-q\x1b[0m] [--] sub [\x1b[36m--config\x1b[0m \x1b[4mfile\x1b[0m]",
+			width: 30,
+			want:  "\x1b[1mUsage:\x1b[0m cmd [\x1b[36m--quiet\x1b[0m|\x1b[36m-q\x1b[0m] [--]\n       sub [\x1b[36m--config\x1b[0m \x1b[4mfile\x1b[0m]",
+		},
+		{
+			name:  "long words",
+			text:  "a https://example.com/some/very/long/path b",
+			width: 20,
+			want:  "a\nhttps://example.com/some/very/long/path\nb",
+		},
+		{
+			name:  "too narrow",
+			text:  "                  the quick brown fox",
+			width: 30,
+			want:  "                  the quick brown fox",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := wrap(tt.text, tt.width); got != tt.want {
+				t.Errorf("wrap() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_splitWords(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"empty", "", nil},
+		{"words", "a  b c ", []string{"a", "b", "c"}},
+		{"brackets", "exe [--flag value] [--] [ARG [ARG ...]]", []string{"exe", "[--flag value]", "[--]", "[ARG [ARG ...]]"}},
+		{"unbalanced brackets", "a [b c", []string{"a", "[b", "c"}},
+		{"styled brackets", "[\x1b[36m--flag\x1b[0m value] b", []string{"[\x1b[36m--flag\x1b[0m value]", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := splitWords(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitWords() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
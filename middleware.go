@@ -0,0 +1,37 @@
+//spellchecker:words goprogram
+package goprogram
+
+// RunFunc runs a command in the given context.
+// It must return nil or an error with an exit code.
+type RunFunc[E any, P any, F any, R Requirement[F]] func(context Context[E, P, F, R]) error
+
+// Middleware wraps the execution of a command, see Program.Use.
+//
+// A middleware is invoked with the context the command is about to run in, and a function next that runs the command.
+// It typically calls next exactly once, and returns the error returned by it.
+// It may pass a modified context to next, for example with a different IOStream.
+// It may also observe the duration of next, inspect or replace the returned error, or recover from a panic inside next.
+// A middleware that does not call next prevents the command from running.
+//
+// The command being run is described by context.Description.
+type Middleware[E any, P any, F any, R Requirement[F]] func(context Context[E, P, F, R], next RunFunc[E, P, F, R]) error
+
+// Use adds middleware wrapping the execution of every command.
+//
+// Middleware is composed in the order it was added: the first middleware is invoked first, and the Run method of the command last.
+// It is invoked after the BeforeCommand hook, and applies to commands invoked using Main, Exec, REPL and RunScript alike.
+func (p *Program[E, P, F, R]) Use(mw ...Middleware[E, P, F, R]) {
+	p.middleware = append(p.middleware, mw...)
+}
+
+// runCommand runs command in the given context, wrapped by all middleware.
+func (p Program[E, P, F, R]) runCommand(context Context[E, P, F, R], command Command[E, P, F, R]) error {
+	run := RunFunc[E, P, F, R](command.Run)
+	for i := len(p.middleware) - 1; i >= 0; i-- {
+		mw, next := p.middleware[i], run
+		run = func(context Context[E, P, F, R]) error {
+			return mw(context, next)
+		}
+	}
+	return run(context)
+}
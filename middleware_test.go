@@ -0,0 +1,148 @@
+//spellchecker:words goprogram
+package goprogram //nolint:testpackage // tests internal behavior
+
+//spellchecker:words bytes strings testing github goprogram exit meta pkglib stream
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.tkw01536.de/goprogram/exit"
+	"go.tkw01536.de/goprogram/meta"
+	"go.tkw01536.de/pkglib/stream"
+)
+
+type iRunFunc = RunFunc[tEnvironment, tParameters, tFlags, tRequirements]
+
+var errMiddlewarePanic = exit.NewErrorWithCode("command panicked", exit.ExitPanic)
+
+func TestProgram_Use(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+
+		wantStdout string
+		wantStderr string
+		wantCode   uint8
+		wantTrace  string
+	}{
+		{
+			name:       "middleware composes in order",
+			args:       []string{"echo", "hello"},
+			wantStdout: "[hello]\n",
+			wantTrace:  "first(echo) second(echo) first=<nil>",
+		},
+		{
+			name:       "middleware applies to exec",
+			args:       []string{"exec", "hello"},
+			wantStdout: "[hello]\n",
+			wantTrace:  "first(exec) second(exec) first(echo) second(echo) first=<nil> first=<nil>",
+		},
+		{
+			name:       "middleware observes errors",
+			args:       []string{"fail"},
+			wantStderr: "failed\n",
+			wantCode:   1,
+			wantTrace:  "first(fail) second(fail) first=failed",
+		},
+		{
+			name:       "middleware recovers panics",
+			args:       []string{"panic"},
+			wantStderr: "command panicked: oops\n",
+			wantCode:   255,
+			wantTrace:  "first(panic) second(panic) first=command panicked: oops",
+		},
+		{
+			name:       "middleware swaps stream",
+			args:       []string{"--global-one", "capture", "echo", "hello"},
+			wantStdout: "captured: [hello]\n",
+			wantTrace:  "first(echo) second(echo) first=<nil>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdoutBuffer bytes.Buffer
+			var stderrBuffer bytes.Buffer
+			str := stream.NewIOStream(&stdoutBuffer, &stderrBuffer, nil)
+
+			var trace []string
+
+			program := makeProgram()
+			program.Register(makeEchoCommand("echo"))
+			program.Register(&tCommand[struct{}]{
+				MDesc: iDescription{Command: "fail", Requirements: func(flag meta.Flag) bool { return true }},
+				MRun: func(tCommand[struct{}], iContext) error {
+					return exit.NewErrorWithCode("failed", exit.ExitGeneric)
+				},
+			})
+			program.Register(&tCommand[struct{}]{
+				MDesc: iDescription{Command: "panic", Requirements: func(flag meta.Flag) bool { return true }},
+				MRun: func(tCommand[struct{}], iContext) error {
+					panic("oops")
+				},
+			})
+			program.Register(&tCommand[echoStruct]{
+				MDesc: iDescription{Command: "exec", Requirements: func(flag meta.Flag) bool { return true }},
+				MRun: func(command tCommand[echoStruct], context iContext) error {
+					return context.Exec("echo", command.Positionals.Arguments...)
+				},
+			})
+
+			program.Use(func(context iContext, next iRunFunc) error {
+				trace = append(trace, "first("+context.Description.Command+")")
+				err := next(context)
+				trace = append(trace, fmt.Sprintf("first=%v", err))
+				return err
+			})
+			program.Use(
+				// recover panics
+				func(context iContext, next iRunFunc) (err error) {
+					trace = append(trace, "second("+context.Description.Command+")")
+					defer func() {
+						if r := recover(); r != nil {
+							err = fmt.Errorf("%w: %v", errMiddlewarePanic, r)
+						}
+					}()
+					return next(context)
+				},
+				// capture output when requested
+				func(context iContext, next iRunFunc) error {
+					if context.Args.Flags.GlobalOne != "capture" {
+						return next(context)
+					}
+
+					var buffer bytes.Buffer
+					original := context.IOStream
+					context.IOStream = stream.NewIOStream(&buffer, original.Stderr, original.Stdin)
+					if err := next(context); err != nil {
+						return err
+					}
+					if _, err := original.Printf("captured: %s", buffer.String()); err != nil {
+						return fmt.Errorf("failed to write output: %w", err)
+					}
+					return nil
+				},
+			)
+
+			code, _ := exit.CodeFromError(program.Main(str, "", tt.args))
+
+			if gotCode := uint8(code); gotCode != tt.wantCode {
+				t.Errorf("Program.Main() code = %v, wantCode %v", gotCode, tt.wantCode)
+			}
+			if gotStdout := stdoutBuffer.String(); gotStdout != tt.wantStdout {
+				t.Errorf("Program.Main() stdout = %q, wantStdout %q", gotStdout, tt.wantStdout)
+			}
+			if gotStderr := stderrBuffer.String(); gotStderr != tt.wantStderr {
+				t.Errorf("Program.Main() stderr = %q, wantStderr %q", gotStderr, tt.wantStderr)
+			}
+			if gotTrace := strings.Join(trace, " "); gotTrace != tt.wantTrace {
+				t.Errorf("Program.Main() trace = %q, wantTrace %q", gotTrace, tt.wantTrace)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+//spellchecker:words goprogram
+package goprogram
+
+//spellchecker:words time
+import "time"
+
+// Observer is notified about lifecycle events of a program, see Program.Observe.
+//
+// It is invoked with the context the event occurred in, and the event itself.
+// The concrete type of event is one of the Event types in this package.
+// Observers cannot influence the execution of the program; use hooks or middleware for this purpose.
+type Observer[E any, P any, F any, R Requirement[F]] func(context Context[E, P, F, R], event Event)
+
+// Event is a lifecycle event passed to an Observer.
+type Event interface {
+	event()
+}
+
+// KeywordEvent occurs right before the keyword with the given name is expanded.
+type KeywordEvent struct {
+	Name string
+}
+
+// AliasEvent occurs right before the given alias is expanded.
+// When an alias expands into another alias, it occurs once for each alias.
+type AliasEvent struct {
+	Alias Alias
+}
+
+// ParsedEvent occurs once the arguments for a command have been parsed.
+// The parsed arguments are found in context.Args, the command in context.Description.
+type ParsedEvent struct{}
+
+// EnvironmentEvent occurs once the environment for a command has been created.
+// The environment is found in context.Environment.
+type EnvironmentEvent struct{}
+
+// RunStartEvent occurs right before a command is run, after the BeforeCommand hook.
+type RunStartEvent struct{}
+
+// RunFinishEvent occurs once a command has finished running, after the AfterCommand hook.
+type RunFinishEvent struct {
+	Err      error         // error returned by the command (and any middleware or hook)
+	Duration time.Duration // time taken to run the command
+}
+
+func (KeywordEvent) event()     {}
+func (AliasEvent) event()       {}
+func (ParsedEvent) event()      {}
+func (EnvironmentEvent) event() {}
+func (RunStartEvent) event()    {}
+func (RunFinishEvent) event()   {}
+
+// Observe adds observers to be notified about lifecycle events.
+// Observers are notified in the order they were added.
+func (p *Program[E, P, F, R]) Observe(observers ...Observer[E, P, F, R]) {
+	p.observers = append(p.observers, observers...)
+}
+
+// notify notifies all observers about the given event.
+func (p Program[E, P, F, R]) notify(context Context[E, P, F, R], event Event) {
+	for _, observer := range p.observers {
+		observer(context, event)
+	}
+}
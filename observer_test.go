@@ -0,0 +1,126 @@
+//spellchecker:words goprogram
+package goprogram //nolint:testpackage // tests internal behavior
+
+//spellchecker:words bytes strings testing time github goprogram exit meta pkglib stream
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"go.tkw01536.de/goprogram/exit"
+	"go.tkw01536.de/goprogram/meta"
+	"go.tkw01536.de/pkglib/stream"
+)
+
+var errObserverReplaced = exit.NewErrorWithCode("replaced", exit.ExitCommandArguments)
+
+func TestProgram_Observe(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+
+		wantStdout string
+		wantCode   uint8
+		wantEvents string
+	}{
+		{
+			name:       "command",
+			args:       []string{"echo", "hello"},
+			wantStdout: "[hello]\n",
+			wantEvents: "parsed(echo) environment start after(<nil>) finish(<nil>)",
+		},
+		{
+			name:       "keyword and alias",
+			args:       []string{"kw", "say", "world"},
+			wantStdout: "[hello world]\n",
+			wantEvents: "keyword(kw) alias(say) parsed(echo) environment start after(<nil>) finish(<nil>)",
+		},
+		{
+			name:       "failing command",
+			args:       []string{"fail"},
+			wantCode:   4,
+			wantEvents: "parsed(fail) environment start after(failed) finish(replaced)",
+		},
+		{
+			name:       "help does not run",
+			args:       []string{"echo", "--help"},
+			wantEvents: "parsed(echo)",
+		},
+		{
+			name:       "unknown command",
+			args:       []string{"unknown"},
+			wantCode:   2,
+			wantEvents: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdoutBuffer bytes.Buffer
+			str := stream.NewIOStream(&stdoutBuffer, nil, nil)
+
+			program := makeProgram()
+			program.Register(makeEchoCommand("echo"))
+			program.Register(&tCommand[struct{}]{
+				MDesc: iDescription{Command: "fail", Requirements: func(flag meta.Flag) bool { return true }},
+				MRun: func(tCommand[struct{}], iContext) error {
+					return exit.NewErrorWithCode("failed", exit.ExitGeneric)
+				},
+			})
+			program.RegisterKeyword("kw", func(args *iArguments, pos *[]string) error {
+				if len(*pos) > 0 {
+					args.Command, *pos = (*pos)[0], (*pos)[1:]
+				}
+				return nil
+			})
+			program.RegisterAlias(Alias{Name: "say", Command: "echo", Args: []string{"hello"}})
+
+			var events []string
+			program.AfterCommand = func(context iContext, command iCommand, err error, took time.Duration) error {
+				if took < 0 {
+					t.Errorf("AfterCommand() got negative duration %v", took)
+				}
+				events = append(events, fmt.Sprintf("after(%v)", err))
+				if err != nil {
+					return errObserverReplaced
+				}
+				return nil
+			}
+			program.Observe(func(context iContext, event Event) {
+				switch event := event.(type) {
+				case KeywordEvent:
+					events = append(events, "keyword("+event.Name+")")
+				case AliasEvent:
+					events = append(events, "alias("+event.Alias.Name+")")
+				case ParsedEvent:
+					events = append(events, "parsed("+context.Description.Command+")")
+				case EnvironmentEvent:
+					events = append(events, "environment")
+				case RunStartEvent:
+					events = append(events, "start")
+				case RunFinishEvent:
+					events = append(events, fmt.Sprintf("finish(%v)", event.Err))
+				}
+			})
+
+			code, _ := exit.CodeFromError(program.Main(str, "", tt.args))
+
+			if gotCode := uint8(code); gotCode != tt.wantCode {
+				t.Errorf("Program.Main() code = %v, wantCode %v", gotCode, tt.wantCode)
+			}
+			if tt.wantStdout != "" {
+				if gotStdout := stdoutBuffer.String(); gotStdout != tt.wantStdout {
+					t.Errorf("Program.Main() stdout = %q, wantStdout %q", gotStdout, tt.wantStdout)
+				}
+			}
+			if gotEvents := strings.Join(events, " "); gotEvents != tt.wantEvents {
+				t.Errorf("Program.Main() events = %q, wantEvents %q", gotEvents, tt.wantEvents)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+//spellchecker:words goprogram
+package goprogram
+
+//spellchecker:words runtime debug github goprogram exit pkglib stream
+import (
+	"fmt"
+	"runtime/debug"
+
+	"go.tkw01536.de/goprogram/exit"
+	"go.tkw01536.de/pkglib/stream"
+)
+
+var errProgramPanic = exit.NewErrorWithCode("panic", exit.ExitPanic)
+
+// recoverPanic recovers from a panic (if any), and replaces *err with an error using exit.ExitPanic.
+// When p.Debug is set, the stack trace of the panic is written to the standard error of str.
+//
+// It must be invoked directly using defer.
+func (p Program[E, P, F, R]) recoverPanic(str stream.IOStream, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	if p.Debug {
+		_, _ = str.EPrintf("%s", debug.Stack()) // no way to report the failure
+	}
+	*err = fmt.Errorf("%w: %v", errProgramPanic, r)
+}
+
+// runRecovered is like runWithTimeout, but turns a panic of the command into an error, see recoverPanic.
+// This allows the caller to invoke AfterCommand and notify observers even when the command panics.
+func (p Program[E, P, F, R]) runRecovered(context Context[E, P, F, R], command Command[E, P, F, R]) (err error) {
+	defer p.recoverPanic(context.IOStream, &err)
+	return p.runWithTimeout(context, command)
+}
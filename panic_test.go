@@ -0,0 +1,173 @@
+//spellchecker:words goprogram
+package goprogram //nolint:testpackage // tests internal behavior
+
+//spellchecker:words bytes errors strings testing time github goprogram exit meta pkglib stream
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.tkw01536.de/goprogram/exit"
+	"go.tkw01536.de/goprogram/meta"
+	"go.tkw01536.de/pkglib/stream"
+)
+
+func TestProgram_Main_panic(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		args  []string
+		debug bool
+
+		wantStdout string
+		wantStderr string
+		wantTrace  bool
+	}{
+		{
+			name:       "panic in run",
+			args:       []string{"panic", "run"},
+			wantStderr: "panic: run\n",
+		},
+		{
+			name:       "panic in run with debug",
+			args:       []string{"panic", "run"},
+			debug:      true,
+			wantStderr: "panic: run\n",
+			wantTrace:  true,
+		},
+		{
+			name:       "panic in keyword",
+			args:       []string{"kw"},
+			wantStderr: "panic: keyword\n",
+		},
+		{
+			name:       "panic in hook",
+			args:       []string{"panic", "hook"},
+			wantStderr: "panic: hook\n",
+		},
+		{
+			name:       "panic in environment",
+			args:       []string{"panic", "environment"},
+			wantStderr: "panic: environment\n",
+		},
+		{
+			name:       "panic in exec",
+			args:       []string{"exec", "run"},
+			wantStdout: "exec returned: panic: run\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdoutBuffer bytes.Buffer
+			var stderrBuffer bytes.Buffer
+			str := stream.NewIOStream(&stdoutBuffer, &stderrBuffer, nil)
+
+			type panicStruct = struct {
+				Where string `required:"1-1"`
+			}
+
+			program := makeProgram()
+			program.Debug = tt.debug
+			program.Register(&tCommand[panicStruct]{
+				MDesc: iDescription{Command: "panic", Requirements: func(flag meta.Flag) bool { return true }},
+				MRun: func(command tCommand[panicStruct], context iContext) error {
+					panic(command.Positionals.Where)
+				},
+			})
+			program.Register(&tCommand[panicStruct]{
+				MDesc: iDescription{Command: "exec", Requirements: func(flag meta.Flag) bool { return true }},
+				MRun: func(command tCommand[panicStruct], context iContext) error {
+					err := context.Exec("panic", command.Positionals.Where)
+					_, _ = context.Printf("exec returned: %v\n", err)
+					return nil
+				},
+			})
+			program.RegisterKeyword("kw", func(args *iArguments, pos *[]string) error {
+				panic("keyword")
+			})
+			program.BeforeCommand = func(context iContext, command iCommand) error {
+				if len(context.Args.pos) > 0 && context.Args.pos[0] == "hook" {
+					panic("hook")
+				}
+				return nil
+			}
+			program.NewEnvironment = func(params tParameters, context iContext) (tEnvironment, error) {
+				if len(context.Args.pos) > 0 && context.Args.pos[0] == "environment" {
+					panic("environment")
+				}
+				return "", nil
+			}
+
+			code, _ := exit.CodeFromError(program.Main(str, "", tt.args))
+
+			wantCode := exit.ExitPanic
+			if tt.wantStderr == "" {
+				wantCode = exit.ExitZero
+			}
+			if code != wantCode {
+				t.Errorf("Program.Main() code = %v, wantCode %v", code, wantCode)
+			}
+			if gotStdout := stdoutBuffer.String(); gotStdout != tt.wantStdout {
+				t.Errorf("Program.Main() stdout = %q, wantStdout %q", gotStdout, tt.wantStdout)
+			}
+
+			gotStderr := stderrBuffer.String()
+			if gotTrace := strings.Contains(gotStderr, "runtime/debug.Stack"); gotTrace != tt.wantTrace {
+				t.Errorf("Program.Main() printed stack trace = %v, want %v", gotTrace, tt.wantTrace)
+			}
+			if tt.wantTrace {
+				gotStderr = gotStderr[strings.LastIndex(gotStderr[:len(gotStderr)-1], "\n")+1:]
+			}
+			if gotStderr != tt.wantStderr {
+				t.Errorf("Program.Main() stderr = %q, wantStderr %q", gotStderr, tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestProgram_Main_panicAfterCommand(t *testing.T) {
+	t.Parallel()
+
+	program := makeProgram()
+	program.Register(&tCommand[struct{}]{
+		MDesc: iDescription{Command: "panic", Requirements: func(flag meta.Flag) bool { return true }},
+		MRun: func(command tCommand[struct{}], context iContext) error {
+			panic("run")
+		},
+	})
+
+	var afterErr error
+	program.AfterCommand = func(context iContext, command iCommand, err error, took time.Duration) error {
+		afterErr = err
+		return err
+	}
+
+	var finishErr error
+	var finished bool
+	program.Observe(func(context iContext, event Event) {
+		if finish, ok := event.(RunFinishEvent); ok {
+			finished = true
+			finishErr = finish.Err
+		}
+	})
+
+	str := stream.NewIOStream(nil, nil, nil)
+	if code, _ := exit.CodeFromError(program.Main(str, "", []string{"panic"})); code != exit.ExitPanic {
+		t.Errorf("Program.Main() code = %v, want %v", code, exit.ExitPanic)
+	}
+
+	if !errors.Is(afterErr, errProgramPanic) {
+		t.Errorf("AfterCommand got error %v, want panic error", afterErr)
+	}
+	if !finished {
+		t.Error("observer did not receive RunFinishEvent")
+	}
+	if !errors.Is(finishErr, errProgramPanic) {
+		t.Errorf("RunFinishEvent.Err = %v, want panic error", finishErr)
+	}
+}
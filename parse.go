@@ -1,12 +1,13 @@
 //spellchecker:words goprogram
 package goprogram
 
-//spellchecker:words slices github goprogram exit parser
+//spellchecker:words slices github goprogram exit meta parser
 import (
 	"fmt"
 	"slices"
 
 	"go.tkw01536.de/goprogram/exit"
+	"go.tkw01536.de/goprogram/meta"
 	"go.tkw01536.de/goprogram/parser"
 )
 
@@ -17,23 +18,29 @@ var (
 	errParseArgsUnknownError    = exit.NewErrorWithCode("unable to parse arguments", exit.ExitGeneralArguments)
 )
 
-// parseProgramFlags parses program-wide arguments.
+// parseProgramFlags parses program-wide arguments into args.
 //
 // In particular, it *does not* parse command specific arguments.
 // Any flags are just returned as unparsed positionals.
 //
+// Global flags are bound to environment variables as configured by p.AutoEnv.
+// When config is non-nil, it provides default values for global flags.
+//
 // When parsing fails, returns an error with an exit code.
 //
 //nolint:wrapcheck
-func (args *Arguments[F]) parseProgramFlags(argv []string) error {
+func (p Program[E, P, F, R]) parseProgramFlags(args *Arguments[F], argv []string, config *configFile) error {
 	var err error
 
-	argsParser := parser.NewArgumentsParser(args)
+	argsParser := p.argumentsParser(args)
+	if err := config.apply(argsParser, "", "flags"); err != nil {
+		return err
+	}
 	args.pos, err = argsParser.ParseArgs(argv)
 
 	// intercept unknown flags
 	if parser.IsUnknownFlag(err) {
-		err = fmt.Errorf("%w: %w", errParseArgsUnknownError, err)
+		err = fmt.Errorf("%w: %w%s", errParseArgsUnknownError, err, suggestFlags(err, p.globalOptions()))
 	}
 
 	// store the arguments we got and complain if there are none.
@@ -73,11 +80,14 @@ var errParseArgCount = exit.NewErrorWithCode("wrong number of positional argumen
 func (context *Context[E, P, F, R]) use(command Command[E, P, F, R]) error {
 	context.Description = command.Description()
 
-	context.parser = parser.NewCommandParser(command)
+	context.parser = context.Program.commandParser(command)
+	if err := context.config.apply(context.parser, context.Description.Command); err != nil {
+		return err
+	}
 
 	// specifically intercept the "--help" and "-h" arguments.
 	// this prevents any kind of side effect from occurring.
-	if slices.Contains(context.Args.pos, "--help") || slices.Contains(context.Args.pos, "-h") {
+	if hasHelpFlag(context.Args.pos) {
 		context.Args.Universals.Help = true
 		return nil
 	}
@@ -100,6 +110,11 @@ func (context *Context[E, P, F, R]) use(command Command[E, P, F, R]) error {
 	return nil
 }
 
+// hasHelpFlag checks if the "--help" or "-h" arguments are contained in pos.
+func hasHelpFlag(pos []string) bool {
+	return slices.Contains(pos, "--help") || slices.Contains(pos, "-h")
+}
+
 var errWrongArguments = exit.NewErrorWithCode("wrong arguments", exit.ExitCommandArguments)
 
 // parseCommandFlags uses the parser to parse flags passed directly to the command.
@@ -118,8 +133,31 @@ func (context *Context[E, P, F, R]) parseCommandFlags() (err error) {
 
 	// if an error occurred, return it!
 	if err != nil {
-		err = fmt.Errorf("%w for %s: %w", errWrongArguments, context.Args.Command, err)
+		err = fmt.Errorf("%w for %s: %w%s", errWrongArguments, context.Args.Command, err, suggestFlags(err, context.parser.Flags()))
 	}
 
 	return err
 }
+
+// suggestFlags returns a hint suggesting flags similar to the unknown flag err refers to.
+// When err does not refer to an unknown flag, or there are no similar flags, returns the empty string.
+func suggestFlags(err error, flags []meta.Flag) string {
+	name, ok := parser.UnknownFlagName(err)
+	if !ok {
+		return ""
+	}
+
+	var candidates []string
+	for _, flag := range flags {
+		candidates = append(candidates, flag.Long...)
+	}
+
+	suggestions := suggest(name, candidates)
+	if len(suggestions) == 0 {
+		return ""
+	}
+	for i, suggestion := range suggestions {
+		suggestions[i] = "--" + suggestion
+	}
+	return ", did you mean " + fmtSuggestions(suggestions) + "?"
+}
@@ -26,7 +26,15 @@ func NewFlag(option *flags.Option) (flag meta.Flag) {
 		flag.Long = []string{long}
 	}
 
-	flag.FieldName = option.Field().Name
+	field := option.Field()
+	flag.FieldName = field.Name
+
+	// boolean flags (and slices thereof) do not take a value
+	tp := field.Type
+	for tp != nil && (tp.Kind() == reflect.Slice || tp.Kind() == reflect.Ptr) {
+		tp = tp.Elem()
+	}
+	flag.Boolean = tp != nil && tp.Kind() == reflect.Bool
 
 	flag.Value = option.ValueName
 
@@ -42,6 +50,8 @@ func NewFlag(option *flags.Option) (flag meta.Flag) {
 
 	flag.Choices = option.Choices
 
+	flag.Env = option.EnvKeyWithNamespace()
+
 	return
 }
 
@@ -58,6 +68,20 @@ func AllFlagsOf(data any) []meta.Flag {
 	}.Flags()
 }
 
+// AllFlagsWithEnv is like AllFlags, but first binds flags to environment variables using BindEnv and prefix.
+// When prefix is empty, flags are not bound.
+func AllFlagsWithEnv[T any](prefix string) []meta.Flag {
+	data := new(T)
+	p := Parser{
+		parser: flags.NewParser(data, flags.None),
+		tp:     reflect.TypeOf(data).Elem(),
+	}
+	if prefix != "" {
+		p.BindEnv(prefix)
+	}
+	return p.Flags()
+}
+
 // NewPositional creates a new Positional from a flag argument.
 func NewPositional(arg *flags.Arg, field reflect.StructField) (pos meta.Positional) {
 	pos.Value = arg.Name
@@ -27,5 +27,6 @@ func NewCommandParser(command any) (p Parser) {
 func NewArgumentsParser(args any) Parser {
 	return Parser{
 		parser: flags.NewParser(args, flags.PassAfterNonOption|flags.PassDoubleDash),
+		tp:     reflect.TypeOf(args).Elem(),
 	}
 }
@@ -3,10 +3,12 @@
 //spellchecker:words parser
 package parser
 
-//spellchecker:words errors reflect github jessevdk flags goprogram meta pkglib reflectx
+//spellchecker:words errors reflect slices strings github jessevdk flags goprogram meta pkglib reflectx
 import (
 	"errors"
 	"reflect"
+	"slices"
+	"strings"
 
 	"github.com/jessevdk/go-flags"
 	"go.tkw01536.de/goprogram/meta"
@@ -54,6 +56,140 @@ func IsUnknownFlag(err error) bool {
 	return errors.As(err, &flagError) && flagError.Type == flags.ErrUnknownFlag
 }
 
+// unknownFlagPrefix and unknownFlagSuffix surround the name of the flag in the message of an unknown flag error.
+//
+// The "github.com/jessevdk/go-flags" package does not expose the name of an unknown flag other than in the message.
+// The message is generated using the format "unknown flag `%s'" (as of v1.6.1), see TestUnknownFlagName.
+const (
+	unknownFlagPrefix = "unknown flag `"
+	unknownFlagSuffix = "'"
+)
+
+// UnknownFlagName returns the name of the unknown flag err refers to.
+// The name does not include any leading dashes.
+//
+// When err does not indicate an unknown flag, or the name cannot be determined, returns the empty string and false.
+func UnknownFlagName(err error) (name string, ok bool) {
+	var flagError *flags.Error
+	if !errors.As(err, &flagError) || flagError.Type != flags.ErrUnknownFlag {
+		return "", false
+	}
+
+	name, ok = strings.CutPrefix(flagError.Message, unknownFlagPrefix)
+	if !ok {
+		return "", false
+	}
+	name, ok = strings.CutSuffix(name, unknownFlagSuffix)
+	if !ok || name == "" {
+		return "", false
+	}
+	return name, true
+}
+
+// BindEnv binds options of p to environment variables named using EnvName, prefix and their long name.
+// Options that already have an environment variable (specified using the "env" tag), or do not have a long name, are not bound.
+//
+// When groups are given, only options inside a group with one of the given names (specified using the "group" tag) are bound.
+func (p Parser) BindEnv(prefix string, groups ...string) {
+	p.eachOption(groups, func(option *flags.Option) {
+		if option.EnvDefaultKey == "" && option.LongName != "" {
+			option.EnvDefaultKey = EnvName(prefix, option.LongName)
+		}
+	})
+}
+
+// SetDefault sets the default value of the option with the given long name to values.
+// As with the "default" tag, values are used only when the option is neither passed as an argument nor set in the environment.
+// Groups restrict the options considered in the same way as for BindEnv.
+//
+// Values are validated against the type and choices of the option before being set.
+// If there is no option with the given name, returns false and a nil error.
+func (p Parser) SetDefault(name string, values []string, groups ...string) (ok bool, err error) {
+	option := p.findOption(name, groups)
+	if option == nil {
+		return false, nil
+	}
+
+	// validate the values using a fresh parser, as setting them marks the option as set.
+	// options backed by functions are not validated, as this would call the function.
+	if p.tp != nil && option.Field().Type.Kind() != reflect.Func {
+		fresh := Parser{parser: flags.NewParser(reflect.New(p.tp).Interface(), flags.None), tp: p.tp}
+		if check := fresh.findOption(name, groups); check != nil {
+			for _, value := range values {
+				if err := check.Set(&value); err != nil {
+					return true, err
+				}
+			}
+		}
+	}
+
+	option.Default = values
+	return true, nil
+}
+
+// AddGroup adds a group with the given name to p, holding options specified by data.
+// Data must be a pointer to a struct annotated in the same way as the data of p.
+func (p Parser) AddGroup(name string, data any) error {
+	if p.parser == nil {
+		return nil
+	}
+	_, err := p.parser.AddGroup(name, "", data)
+	return err
+}
+
+// findOption finds the option with the given long name.
+// Groups restrict the options considered in the same way as for BindEnv.
+func (p Parser) findOption(name string, groups []string) (found *flags.Option) {
+	p.eachOption(groups, func(option *flags.Option) {
+		if found == nil && option.LongName == name {
+			found = option
+		}
+	})
+	return
+}
+
+// eachOption calls f for each option of p.
+// When groups are given, only options inside a group with one of the given names are considered.
+func (p Parser) eachOption(groups []string, f func(option *flags.Option)) {
+	if p.parser == nil {
+		return
+	}
+
+	var visit func(group *flags.Group, included bool)
+	visit = func(group *flags.Group, included bool) {
+		included = included || slices.Contains(groups, group.ShortDescription)
+		if included {
+			for _, option := range group.Options() {
+				f(option)
+			}
+		}
+		for _, child := range group.Groups() {
+			visit(child, included)
+		}
+	}
+
+	for _, group := range p.parser.Groups() {
+		visit(group, len(groups) == 0)
+	}
+}
+
+// EnvName returns the name of the environment variable for a flag with the given prefix and long name.
+// It consists of the upper-cased prefix and name joined by an underscore, with any character other than a letter or digit replaced by an underscore.
+//
+// For example, EnvName("exe", "some-flag") returns "EXE_SOME_FLAG".
+func EnvName(prefix, name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		default:
+			return '_'
+		}
+	}, prefix+"_"+name)
+}
+
 // options collects all options contained in p or inside a group of p.
 func (p Parser) args() (options []*flags.Arg) {
 	if p.parser == nil {
@@ -0,0 +1,147 @@
+//spellchecker:words parser
+package parser_test
+
+//spellchecker:words errors testing github jessevdk flags goprogram parser
+import (
+	"errors"
+	"testing"
+
+	"github.com/jessevdk/go-flags"
+	"go.tkw01536.de/goprogram/parser"
+)
+
+func TestUnknownFlagName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		err      error
+		wantName string
+		wantOK   bool
+	}{
+		{"nil error", nil, "", false},
+		{"unrelated error", errors.New("unknown flag `test'"), "", false},
+		{"help error", &flags.Error{Type: flags.ErrHelp, Message: "help"}, "", false},
+		{"unknown long flag", &flags.Error{Type: flags.ErrUnknownFlag, Message: "unknown flag `test'"}, "test", true},
+		{"unknown short flag", &flags.Error{Type: flags.ErrUnknownFlag, Message: "unknown flag `t'"}, "t", true},
+		{"unexpected message", &flags.Error{Type: flags.ErrUnknownFlag, Message: "no such flag: test"}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotName, gotOK := parser.UnknownFlagName(tt.err)
+			if gotName != tt.wantName {
+				t.Errorf("UnknownFlagName() name = %q, want %q", gotName, tt.wantName)
+			}
+			if gotOK != tt.wantOK {
+				t.Errorf("UnknownFlagName() ok = %v, want %v", gotOK, tt.wantOK)
+			}
+		})
+	}
+}
+
+// TestUnknownFlagName_parse checks that UnknownFlagName understands the errors produced by the version of go-flags in use.
+func TestUnknownFlagName_parse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantName string
+	}{
+		{"unknown long flag", []string{"--test"}, "test"},
+		{"unknown long flag with value", []string{"--test=value"}, "test"},
+		{"unknown short flag", []string{"-t"}, "t"},
+		{"unknown short flag in cluster", []string{"-kt"}, "t"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var args struct {
+				Known bool `long:"known" short:"k"`
+			}
+			_, err := parser.NewArgumentsParser(&args).ParseArgs(tt.args)
+
+			gotName, gotOK := parser.UnknownFlagName(err)
+			if !gotOK || gotName != tt.wantName {
+				t.Errorf("UnknownFlagName() = %q, %v, want %q, true (error %v)", gotName, gotOK, tt.wantName, err)
+			}
+		})
+	}
+}
+
+func TestAllFlags_boolean(t *testing.T) {
+	t.Parallel()
+
+	flags := parser.AllFlags[struct {
+		Switch   bool     `long:"switch"`
+		Switches []bool   `long:"switches"`
+		Value    string   `long:"value"`
+		Values   []string `long:"values"`
+	}]()
+
+	want := []bool{true, true, false, false}
+	if len(flags) != len(want) {
+		t.Fatalf("AllFlags() returned %d flags, want %d", len(flags), len(want))
+	}
+	for i, flag := range flags {
+		if flag.Boolean != want[i] {
+			t.Errorf("AllFlags()[%d].Boolean = %v, want %v", i, flag.Boolean, want[i])
+		}
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		prefix string
+		name   string
+		want   string
+	}{
+		{"exe", "flag", "EXE_FLAG"},
+		{"exe", "some-flag", "EXE_SOME_FLAG"},
+		{"my-exe", "flag2", "MY_EXE_FLAG2"},
+	}
+	for _, tt := range tests {
+		if got := parser.EnvName(tt.prefix, tt.name); got != tt.want {
+			t.Errorf("EnvName(%q, %q) = %q, want %q", tt.prefix, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAllFlagsWithEnv(t *testing.T) {
+	t.Parallel()
+
+	type Flags struct {
+		Plain    string `long:"plain"`
+		Explicit string `env:"EXPLICIT" long:"explicit"`
+		Short    bool   `short:"s"`
+	}
+
+	tests := []struct {
+		name   string
+		prefix string
+		want   []string
+	}{
+		{"no prefix", "", []string{"", "EXPLICIT", ""}},
+		{"with prefix", "exe", []string{"EXE_PLAIN", "EXPLICIT", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			flags := parser.AllFlagsWithEnv[Flags](tt.prefix)
+			if len(flags) != len(tt.want) {
+				t.Fatalf("AllFlagsWithEnv() returned %d flags, want %d", len(flags), len(tt.want))
+			}
+			for i, flag := range flags {
+				if flag.Env != tt.want[i] {
+					t.Errorf("AllFlagsWithEnv()[%d].Env = %q, want %q", i, flag.Env, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -3,13 +3,19 @@
 //spellchecker:words goprogram
 package goprogram
 
-//spellchecker:words context github goprogram exit meta pkglib stream
+//spellchecker:words context errors strconv strings time github goprogram exit meta parser terminal pkglib stream
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"go.tkw01536.de/goprogram/exit"
 	"go.tkw01536.de/goprogram/meta"
+	"go.tkw01536.de/goprogram/parser"
+	"go.tkw01536.de/goprogram/terminal"
 	"go.tkw01536.de/pkglib/stream"
 )
 
@@ -28,7 +34,7 @@ import (
 // The type of (global) command line flags F is backed by a struct type.
 // It is jointed by a type of Requirements R which impose restrictions on flags for commands.
 //
-// Internally a program also contains a list of commands, keywords and aliases.
+// Internally a program also contains a list of commands, groups, keywords, aliases and middleware.
 //
 // See the Main method for a description of how program execution takes place.
 type Program[E any, P any, F any, R Requirement[F]] struct {
@@ -50,7 +56,7 @@ type Program[E any, P any, F any, R Requirement[F]] struct {
 	//
 	// If the context is closed before a command would be invoked, then the command is not invoked.
 	//
-	// See also [env.NewOSContext].
+	// See also [env.NewOSContext], which handles interrupt and termination signals.
 	NewContext func(params *P, parent context.Context) (context.Context, ContextCleanupFunc[E, P, F, R], error)
 
 	// The NewEnvironment function is used to create a new environment.
@@ -62,6 +68,7 @@ type Program[E any, P any, F any, R Requirement[F]] struct {
 
 	// BeforeKeyword, BeforeAlias and BeforeCommand (if non-nil) are invoked right before their respective datum is executed.
 	// They are intended to act as a guard before executing a particular datum.
+	// When an alias expands into another alias, BeforeAlias is invoked once for each alias.
 	//
 	// The returned error must be nil or of type exit.Error.
 	// When non-nil, the error is returned to the caller of Main().
@@ -69,11 +76,77 @@ type Program[E any, P any, F any, R Requirement[F]] struct {
 	BeforeAlias   func(context Context[E, P, F, R], alias Alias) error
 	BeforeCommand func(context Context[E, P, F, R], command Command[E, P, F, R]) error
 
-	// Commands, Keywords, and Aliases associated with this program.
+	// AfterCommand (if non-nil) is invoked right after a command has run, regardless of whether it succeeded.
+	// It is passed the error returned by the command (after any middleware) and the time it took to run.
+	// When the command panics, the error uses exit.ExitPanic, see Main.
+	// It is not invoked when the command did not run, for example because BeforeCommand returned an error.
+	//
+	// The returned error must be nil or of type exit.Error.
+	// It replaces the error returned by the command; to keep it, return err.
+	AfterCommand func(context Context[E, P, F, R], command Command[E, P, F, R], err error, took time.Duration) error
+
+	// AbbreviateCommands allows invoking commands, groups and aliases by an abbreviation of their name.
+	// An abbreviation is any prefix of a name that is not the prefix of any other name on the same level.
+	//
+	// Keywords and exact names always take priority over abbreviations.
+	// An ambiguous abbreviation results in an error listing the possible names.
+	AbbreviateCommands bool
+
+	// AutoEnv binds global and command flags to environment variables named after the executable and their long name.
+	// For example, the global flag "--some-flag" of the executable "exe" is bound to "EXE_SOME_FLAG"; see parser.EnvName.
+	// Command flags additionally include the words of the command, so that commands may use the same flag names.
+	// For example, the flag "--force" of the command "repo list" is bound to "EXE_REPO_LIST_FORCE".
+	// The variable of each flag is shown in its help message.
+	//
+	// Flags with an explicit "env" tag keep their variable; universal flags are never bound.
+	// Values passed on the command line take precedence over the environment, which takes precedence over defaults.
+	AutoEnv bool
+
+	// ConfigFile is the name of a configuration file holding values for global and command flags, for example "config.toml".
+	// When empty, configuration files are not read.
+	//
+	// The file is looked up in a directory named after Info.Executable inside the first XDG configuration directory containing it,
+	// that is $XDG_CONFIG_HOME (defaulting to "~/.config") followed by $XDG_CONFIG_DIRS (defaulting to "/etc/xdg").
+	// The universal "--config" flag may be used to read a different file instead.
+	//
+	// The format of the file is determined by its extension, and may be one of ".toml", ".json", ".yaml" or ".yml".
+	// Top-level keys hold global flags, identified by their long name.
+	// Tables named after a command hold flags of that command; tables named after a group hold further tables for its commands.
+	// Arrays may be used for flags that can be passed multiple times.
+	//
+	// Values from the file take precedence over defaults, but not over the environment or the command line.
+	// Errors in the file, such as unknown keys or invalid values, cause Main to fail with exit.ExitGeneralArguments.
+	ConfigFile string
+
+	// Categories determines the order of the sections on the main usage page.
+	//
+	// When any top-level command or group has a category, the main usage page lists one section per category instead of a single list of commands.
+	// Categories are listed in the order given here, followed by any other categories in alphabetical order.
+	// Commands and groups without a category are listed in a section named "Other", and aliases in a final section named "Aliases".
+	Categories []string
+
+	// UsageWidth is the number of columns usage pages are wrapped to, see meta.Meta.Width.
+	//
+	// When zero and standard output is a terminal, the width is taken from the "COLUMNS" environment variable or, if unset, the size of the terminal.
+	// When negative, or when zero and standard output is not a terminal, usage pages are not wrapped.
+	UsageWidth int
+
+	// Debug enables diagnostic output intended for developers of the program.
+	// Currently, this causes the stack trace of a recovered panic to be written to standard error.
+	Debug bool
+
+	// Commands, Groups, Keywords, and Aliases associated with this program.
 	// They are expanded in order; see Main for details.
 	keywords map[string]Keyword[F]
 	aliases  map[string]Alias
+	groups   map[string]Group
 	commands map[string]Command[E, P, F, R]
+
+	// middleware wrapping the execution of commands, see Use.
+	middleware []Middleware[E, P, F, R]
+
+	// observers notified about lifecycle events, see Observe.
+	observers []Observer[E, P, F, R]
 }
 
 // initContext initializes the context of the context.
@@ -102,21 +175,31 @@ func (p Program[E, P, F, R]) initContextContext(params *P, context *Context[E, P
 // Main takes input / output streams, parameters for the environment and a set of command-line arguments.
 //
 // It first parses these into arguments for a specific command to be executed.
-// Next, it executes any keywords and expands any aliases.
+// Next, it executes any keywords, expands any aliases and descends into any groups.
 // Finally, it executes the requested command or displays a help or version page.
 //
 // For keyword actions, see Keyword.
 // For alias expansion, see Alias.
-// For command execution, see Command.
+// For groups, see Group.
+// For command execution, see Command and Use.
+// For lifecycle events, see Observe.
 //
-// For help pages, see MainUsage, CommandUsage, AliasUsage.
+// For help pages, see MainUsage, GroupUsage, CommandUsage, AliasUsage.
 // For version pages, see FmtVersion.
+// For dynamic completion, see CompleteCommand.
+//
+// When any code invoked by Main calls panic(), Main recovers and returns an error using exit.ExitPanic.
+// See also Debug.
 func (p Program[E, P, F, R]) Main(str stream.IOStream, params P, argv []string) (err error) {
 	// whenever an error occurs, we want it printed
+	var color terminal.ColorMode
 	defer func() {
-		err = exit.Die(str, err)
+		err = exit.DieStyled(str, err, color.Enabled(str.Stderr))
 	}()
 
+	// turn a panic into an error
+	defer p.recoverPanic(str, &err)
+
 	// create a new context
 	context := Context[E, P, F, R]{
 		Context:  context.Background(),
@@ -125,10 +208,34 @@ func (p Program[E, P, F, R]) Main(str stream.IOStream, params P, argv []string)
 	}
 	defer context.handleCleanup()()
 
+	// handle dynamic completion
+	if len(argv) > 0 && argv[0] == CompleteCommand {
+		if err := p.initContextContext(&params, &context); err != nil {
+			return err
+		}
+		return p.complete(context, argv[1:], func(context Context[E, P, F, R]) (E, error) {
+			return p.makeEnvironment(params, context)
+		})
+	}
+
 	// parse flags!
-	if err := context.Args.parseProgramFlags(argv); err != nil {
+	if err := p.parseProgramFlags(&context.Args, argv, nil); err != nil {
+		return err
+	}
+
+	// read the configuration file (if any), and parse flags again to take it into account
+	if context.config, err = p.readConfig(context.Args.config.Config); err != nil {
 		return err
 	}
+	if context.config != nil {
+		context.Args = Arguments[F]{}
+		if err := p.parseProgramFlags(&context.Args, argv, context.config); err != nil {
+			return err
+		}
+	}
+
+	// style errors as requested
+	color = context.Args.Universals.Color
 
 	// initialize the underlying context
 	if err := p.initContextContext(&params, &context); err != nil {
@@ -165,6 +272,7 @@ func (p Program[E, P, F, R]) Exec(context Context[E, P, F, R], command string, p
 			pos:     pos,
 		},
 
+		config: context.config,
 		inExec: true,
 	}
 	defer context.handleCleanup()()
@@ -180,6 +288,8 @@ func (p Program[E, P, F, R]) Exec(context Context[E, P, F, R], command string, p
 
 var (
 	errProgramUnknownCommand = exit.NewErrorWithCode("unknown command", exit.ExitUnknownCommand) //  must be one of %s
+	errProgramMissingCommand = exit.NewErrorWithCode("missing command", exit.ExitUnknownCommand)
+	errProgramAmbiguous      = exit.NewErrorWithCode("ambiguous command", exit.ExitUnknownCommand)
 	errProgramContext        = exit.NewErrorWithCode("context was closed before main could run", exit.ExitContext)
 	errProgramIO             = exit.NewErrorWithCode("failed to write to context", exit.ExitContext)
 )
@@ -188,6 +298,9 @@ var (
 //
 //nolint:wrapcheck
 func (p Program[E, P, F, R]) run(context Context[E, P, F, R], setupEnvironment func(context Context[E, P, F, R]) (E, error)) (err error) {
+	// turn a panic into an error, so that REPL and RunScript can continue
+	defer p.recoverPanic(context.IOStream, &err)
+
 	// expand keyword
 	keyword, hasKeyword := p.keywords[context.Args.Command]
 	if hasKeyword {
@@ -198,6 +311,7 @@ func (p Program[E, P, F, R]) run(context Context[E, P, F, R], setupEnvironment f
 				return err
 			}
 		}
+		p.notify(context, KeywordEvent{Name: context.Args.Command})
 		if err := keyword(&context.Args, &context.Args.pos); err != nil {
 			return err
 		}
@@ -206,11 +320,7 @@ func (p Program[E, P, F, R]) run(context Context[E, P, F, R], setupEnvironment f
 	// handle universals
 	switch {
 	case context.Args.Universals.Help:
-		_, err = context.Println(p.MainUsage().String())
-		if err != nil {
-			return fmt.Errorf("%w: %w", errProgramIO, err)
-		}
-		return nil
+		return p.printUsage(context, p.MainUsage())
 	case context.Args.Universals.Version:
 		_, err = context.Println(p.Info.FmtVersion())
 		if err != nil {
@@ -219,9 +329,17 @@ func (p Program[E, P, F, R]) run(context Context[E, P, F, R], setupEnvironment f
 		return nil
 	}
 
+	// expand abbreviations of commands, groups or aliases
+	if context.Args.Command, err = p.expandAbbreviation("", context.Args.Command, true); err != nil {
+		return err
+	}
+
 	// expand the alias (if any)
-	alias, hasAlias := p.aliases[context.Args.Command]
-	if hasAlias {
+	aliases, err := p.expandAliases(context.Args.Command)
+	if err != nil {
+		return err
+	}
+	for _, alias := range aliases {
 		// invoke BeforeAlias (if any)
 		if p.BeforeAlias != nil {
 			err := p.BeforeAlias(context, alias)
@@ -229,34 +347,41 @@ func (p Program[E, P, F, R]) run(context Context[E, P, F, R], setupEnvironment f
 				return err
 			}
 		}
+		p.notify(context, AliasEvent{Alias: alias})
 		context.Args.Command, context.Args.pos = alias.Invoke(context.Args.pos)
 	}
 
+	// descend into groups
+	if context.Args.Command, context.Args.pos, err = p.descendGroups(context.Args.Command, context.Args.pos); err != nil {
+		return err
+	}
+
+	// we ended up at a group, so there is no command to run.
+	if group, isGroup := p.Group(context.Args.Command); isGroup {
+		if hasHelpFlag(context.Args.pos) {
+			return p.printUsage(context, p.GroupUsage(group))
+		}
+		return fmt.Errorf("%w for %s: must be one of %s", errProgramMissingCommand, group.Name, meta.JoinCommands(p.children(group.Name)))
+	}
+
 	// load the command if we have it
 	command, hasCommand := p.Command(context.Args.Command)
 	if !hasCommand {
-		return fmt.Errorf("%w: must be one of %s", errProgramUnknownCommand, p.FmtCommands())
+		return p.unknownCommand(context.Args.Command)
 	}
 
 	// make the context use the given command
 	if err := context.use(command); err != nil {
 		return err
 	}
+	p.notify(context, ParsedEvent{})
 
 	// write out help information (if given)
 	if context.Args.Universals.Help {
-		if hasAlias {
-			_, err = context.Println(p.AliasUsage(context, alias).String())
-			if err != nil {
-				return fmt.Errorf("%w: %w", errProgramIO, err)
-			}
-			return nil
-		}
-		_, err = context.Println(p.CommandUsage(context).String())
-		if err != nil {
-			return fmt.Errorf("%w: %w", errProgramIO, err)
+		if len(aliases) > 0 {
+			return p.printUsage(context, p.AliasUsage(context, aliases[0]))
 		}
-		return nil
+		return p.printUsage(context, p.CommandUsage(context))
 	}
 
 	// call the AfterParse hook
@@ -270,6 +395,7 @@ func (p Program[E, P, F, R]) run(context Context[E, P, F, R], setupEnvironment f
 	if context.Environment, err = setupEnvironment(context); err != nil {
 		return err
 	}
+	p.notify(context, EnvironmentEvent{})
 
 	// invoke BeforeCommand (if any)
 	if p.BeforeCommand != nil {
@@ -280,12 +406,134 @@ func (p Program[E, P, F, R]) run(context Context[E, P, F, R], setupEnvironment f
 	}
 
 	// check that the context isn't closed!
-	if err := context.Context.Err(); err != nil {
-		return fmt.Errorf("%w: %w", errProgramContext, err)
+	if err := closedContextError(context.Context); err != nil {
+		return err
+	}
+
+	// warn about deprecated commands
+	if deprecated := context.Description.Deprecated; deprecated != nil {
+		_, _ = context.EPrintf("warning: %s\n", deprecated.Notice(context.Description.Command)) // no way to report the failure
 	}
 
 	// do the command!
-	return command.Run(context)
+	p.notify(context, RunStartEvent{})
+	start := time.Now()
+	err = p.runRecovered(context, command)
+	took := time.Since(start)
+
+	// when the context was closed while running, the error uses the exit code of the cause (if any).
+	// this is consistent with exceeding the time limit, see runWithTimeout.
+	if cause := contextCause(context.Context); err != nil && cause != nil && !errors.Is(err, cause) {
+		err = fmt.Errorf("%w: %w", cause, err)
+	}
+
+	// invoke AfterCommand (if any)
+	if p.AfterCommand != nil {
+		err = p.AfterCommand(context, command, err, took)
+	}
+	p.notify(context, RunFinishEvent{Err: err, Duration: took})
+
+	return err
+}
+
+// closedContextError returns the error to return when ctx is closed, or nil when it is not.
+// When the cause of ctx holds an exit code (such as an error created by exit.NewSignalError), the returned error uses it.
+func closedContextError(ctx context.Context) error {
+	err := ctx.Err()
+	if err == nil {
+		return nil
+	}
+	if cause := contextCause(ctx); cause != nil {
+		// errProgramContext is only formatted, not wrapped.
+		// Otherwise exit.CodeFromError would find its exit code before the one of cause.
+		return fmt.Errorf("%s: %w", errProgramContext, cause)
+	}
+	return fmt.Errorf("%w: %w", errProgramContext, err)
+}
+
+// contextCause returns the cause of ctx being closed, provided that it holds an exit code.
+// When ctx is not closed, or the cause does not hold an exit code, returns nil.
+func contextCause(ctx context.Context) error {
+	err := ctx.Err()
+	if err == nil {
+		return nil
+	}
+	cause := context.Cause(ctx)
+	if errors.Is(cause, err) {
+		return nil
+	}
+	if _, ok := exit.CodeFromError(cause); !ok {
+		return nil
+	}
+	return cause
+}
+
+// descendGroups descends from the given command into groups, taking names of commands or groups from pos.
+// It stops once command is not a group, or pos is empty or starts with a flag.
+func (p Program[E, P, F, R]) descendGroups(command string, pos []string) (string, []string, error) {
+	for p.hasGroup(command) && len(pos) > 0 && !strings.HasPrefix(pos[0], "-") {
+		child, err := p.expandAbbreviation(command, pos[0], false)
+		if err != nil {
+			return "", nil, err
+		}
+		command = joinName(command, child)
+		pos = pos[1:]
+	}
+	return command, pos, nil
+}
+
+// unknownCommand returns an error indicating that the command with the given name does not exist.
+// When there are similarly named commands, aliases or keywords, the error suggests them.
+func (p Program[E, P, F, R]) unknownCommand(name string) error {
+	group := parentName(name)
+
+	candidates := p.children(group)
+	if group == "" {
+		candidates = append(candidates, p.Aliases()...)
+		candidates = append(candidates, p.Keywords()...)
+	}
+
+	suggestions := suggest(baseName(name), candidates)
+	if len(suggestions) == 0 {
+		return fmt.Errorf("%w: must be one of %s", errProgramUnknownCommand, meta.JoinCommands(p.children(group)))
+	}
+
+	for i, suggestion := range suggestions {
+		suggestions[i] = strconv.Quote(joinName(group, suggestion))
+	}
+	return fmt.Errorf("%w %q: did you mean %s?", errProgramUnknownCommand, name, fmtSuggestions(suggestions))
+}
+
+// envPrefix returns the prefix of environment variables flags are bound to.
+// If flags are not bound automatically, returns the empty string.
+func (p Program[E, P, F, R]) envPrefix() string {
+	if !p.AutoEnv {
+		return ""
+	}
+	return p.Info.Executable
+}
+
+// argumentsParser returns a new parser for program-wide arguments stored in args.
+// Global flags are bound to environment variables as configured by p.AutoEnv.
+func (p Program[E, P, F, R]) argumentsParser(args *Arguments[F]) parser.Parser {
+	ap := parser.NewArgumentsParser(args)
+	if p.ConfigFile != "" {
+		_ = ap.AddGroup("config", &args.config) // statically known to be valid
+	}
+	if prefix := p.envPrefix(); prefix != "" {
+		ap.BindEnv(prefix, "flags")
+	}
+	return ap
+}
+
+// commandParser returns a new parser for command.
+// Flags are bound to environment variables as configured by p.AutoEnv, prefixed additionally by the words of the command.
+func (p Program[E, P, F, R]) commandParser(command Command[E, P, F, R]) parser.Parser {
+	cp := parser.NewCommandParser(command)
+	if prefix := p.envPrefix(); prefix != "" {
+		cp.BindEnv(prefix + "_" + command.Description().Command)
+	}
+	return cp
 }
 
 // makeEnvironment creates a new environment for the given command.
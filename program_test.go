@@ -3,14 +3,16 @@ package goprogram //nolint:testpackage
 
 //spellchecker:words positionals nolint testpackage
 
-//spellchecker:words bytes path filepath reflect runtime testing time github goprogram exit meta parser pkglib stream testlib
+//spellchecker:words bytes path filepath reflect runtime strings testing time github goprogram exit meta parser pkglib stream testlib
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -24,6 +26,18 @@ import (
 // It is reused across the test suite, however there is no versioning guarantee.
 // It may change in a future revision of the test suite.
 
+// TestMain pins the automatic color mode, so that output compared by tests is never styled.
+// Without this, results would depend on the NO_COLOR and CLICOLOR_FORCE variables of the shell running the tests.
+func TestMain(m *testing.M) {
+	if err := os.Setenv("NO_COLOR", "1"); err != nil {
+		panic(err)
+	}
+	if err := os.Unsetenv("CLICOLOR_FORCE"); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
 // Environment of each command is a single string value.
 // Parameters to initialize each command is also a string value.
 type tEnvironment string
@@ -180,12 +194,21 @@ func TestProgram_Main(t *testing.T) {
 			wantCode:   3,
 		},
 
+		{
+			name:        "unknown general args with suggestion",
+			args:        []string{"--global-on", "value", "fake"},
+			positionals: makeTPM_Positionals[struct{}](),
+
+			wantStderr: "unable to parse arguments: unknown flag `global-on', did you mean --global-one?\n",
+			wantCode:   3,
+		},
+
 		{
 			name:        "display help",
 			args:        []string{"--help"},
 			positionals: makeTPM_Positionals[struct{}](),
 
-			wantStdout: "Usage: exe [--help|-h] [--version|-v] [--global-one|-a] [--global-two|-b] [--] COMMAND [ARGS...]\n\nsomething something dark side\n\n   -h, --help\n      print a help message and exit\n\n   -v, --version\n      print a version message and exit\n\n   -a, --global-one\n      \n\n   -b, --global-two\n      \n\n   COMMAND [ARGS...]\n      Command to call. One of \"fake\". See individual commands for more help.\n",
+			wantStdout: "Usage: exe [--help|-h] [--version|-v] [--timeout duration] [--color when] [--global-one|-a] [--global-two|-b] [--] COMMAND [ARGS...]\n\nsomething something dark side\n\n   -h, --help\n      print a help message and exit\n\n   -v, --version\n      print a version message and exit\n\n   --timeout duration\n      maximum time the command may run\n\n   --color when\n      when to use colors in output (choices: auto, always, never; default auto)\n\n   -a, --global-one\n      \n\n   -b, --global-two\n      \n\n   COMMAND [ARGS...]\n      Command to call. One of \"fake\". See individual commands for more help.\n",
 			wantCode:   0,
 		},
 
@@ -194,7 +217,7 @@ func TestProgram_Main(t *testing.T) {
 			args:        []string{"--help", "fake", "whatever"},
 			positionals: makeTPM_Positionals[struct{}](),
 
-			wantStdout: "Usage: exe [--help|-h] [--version|-v] [--global-one|-a] [--global-two|-b] [--] COMMAND [ARGS...]\n\nsomething something dark side\n\n   -h, --help\n      print a help message and exit\n\n   -v, --version\n      print a version message and exit\n\n   -a, --global-one\n      \n\n   -b, --global-two\n      \n\n   COMMAND [ARGS...]\n      Command to call. One of \"fake\". See individual commands for more help.\n",
+			wantStdout: "Usage: exe [--help|-h] [--version|-v] [--timeout duration] [--color when] [--global-one|-a] [--global-two|-b] [--] COMMAND [ARGS...]\n\nsomething something dark side\n\n   -h, --help\n      print a help message and exit\n\n   -v, --version\n      print a version message and exit\n\n   --timeout duration\n      maximum time the command may run\n\n   --color when\n      when to use colors in output (choices: auto, always, never; default auto)\n\n   -a, --global-one\n      \n\n   -b, --global-two\n      \n\n   COMMAND [ARGS...]\n      Command to call. One of \"fake\". See individual commands for more help.\n",
 			wantCode:   0,
 		},
 
@@ -213,7 +236,7 @@ func TestProgram_Main(t *testing.T) {
 			desc:        iDescription{Requirements: reqAny},
 			positionals: makeTPM_Positionals[struct{}](),
 
-			wantStdout: "Usage: exe [--help|-h] [--version|-v] [--global-one|-a] [--global-two|-b] [--] fake [--stdout|-o message] [--stderr|-e message]\n\nGlobal Arguments:\n\n   -h, --help\n      print a help message and exit\n\n   -v, --version\n      print a version message and exit\n\n   -a, --global-one\n      \n\n   -b, --global-two\n      \n\nCommand Arguments:\n\n   -o, --stdout message\n       (default write to stdout)\n\n   -e, --stderr message\n       (default write to stderr)\n",
+			wantStdout: "Usage: exe [--help|-h] [--version|-v] [--timeout duration] [--color when] [--global-one|-a] [--global-two|-b] [--] fake [--stdout|-o message] [--stderr|-e message]\n\nGlobal Arguments:\n\n   -h, --help\n      print a help message and exit\n\n   -v, --version\n      print a version message and exit\n\n   --timeout duration\n      maximum time the command may run\n\n   --color when\n      when to use colors in output (choices: auto, always, never; default auto)\n\n   -a, --global-one\n      \n\n   -b, --global-two\n      \n\nCommand Arguments:\n\n   -o, --stdout message\n       (default write to stdout)\n\n   -e, --stderr message\n       (default write to stderr)\n",
 			wantCode:   0,
 		},
 
@@ -223,7 +246,7 @@ func TestProgram_Main(t *testing.T) {
 			desc:        iDescription{Requirements: reqAny},
 			positionals: makeTPM_Positionals[struct{}](),
 
-			wantStdout: "Usage: exe [--help|-h] [--version|-v] [--global-one|-a] [--global-two|-b] [--] fake [--stdout|-o message] [--stderr|-e message]\n\nGlobal Arguments:\n\n   -h, --help\n      print a help message and exit\n\n   -v, --version\n      print a version message and exit\n\n   -a, --global-one\n      \n\n   -b, --global-two\n      \n\nCommand Arguments:\n\n   -o, --stdout message\n       (default write to stdout)\n\n   -e, --stderr message\n       (default write to stderr)\n",
+			wantStdout: "Usage: exe [--help|-h] [--version|-v] [--timeout duration] [--color when] [--global-one|-a] [--global-two|-b] [--] fake [--stdout|-o message] [--stderr|-e message]\n\nGlobal Arguments:\n\n   -h, --help\n      print a help message and exit\n\n   -v, --version\n      print a version message and exit\n\n   --timeout duration\n      maximum time the command may run\n\n   --color when\n      when to use colors in output (choices: auto, always, never; default auto)\n\n   -a, --global-one\n      \n\n   -b, --global-two\n      \n\nCommand Arguments:\n\n   -o, --stdout message\n       (default write to stdout)\n\n   -e, --stderr message\n       (default write to stderr)\n",
 			wantCode:   0,
 		},
 
@@ -239,7 +262,7 @@ func TestProgram_Main(t *testing.T) {
 			desc:        iDescription{Requirements: reqAny},
 			positionals: makeTPM_Positionals[struct{}](),
 
-			wantStdout: "Usage: exe [--help|-h] [--version|-v] [--global-one|-a] [--global-two|-b] [--] alias [--] [ARG ...]\n\nalias for `exe fake`. see `exe fake --help` for detailed help page about fake\n\nGlobal Arguments:\n\n   -h, --help\n      print a help message and exit\n\n   -v, --version\n      print a version message and exit\n\n   -a, --global-one\n      \n\n   -b, --global-two\n      \n\nCommand Arguments:\n\n   [ARG ...]\n      arguments to pass after `exe fake`\n",
+			wantStdout: "Usage: exe [--help|-h] [--version|-v] [--timeout duration] [--color when] [--global-one|-a] [--global-two|-b] [--] alias [--] [ARG ...]\n\nalias for `exe fake`. see `exe fake --help` for detailed help page about fake\n\nGlobal Arguments:\n\n   -h, --help\n      print a help message and exit\n\n   -v, --version\n      print a version message and exit\n\n   --timeout duration\n      maximum time the command may run\n\n   --color when\n      when to use colors in output (choices: auto, always, never; default auto)\n\n   -a, --global-one\n      \n\n   -b, --global-two\n      \n\nCommand Arguments:\n\n   [ARG ...]\n      arguments to pass after `exe fake`\n",
 			wantCode:   0,
 		},
 
@@ -255,7 +278,7 @@ func TestProgram_Main(t *testing.T) {
 			desc:        iDescription{Requirements: reqAny},
 			positionals: makeTPM_Positionals[struct{}](),
 
-			wantStdout: "Usage: exe [--help|-h] [--version|-v] [--global-one|-a] [--global-two|-b] [--] alias [--] [ARG ...]\n\nalias for `exe fake`. see `exe fake --help` for detailed help page about fake\n\nGlobal Arguments:\n\n   -h, --help\n      print a help message and exit\n\n   -v, --version\n      print a version message and exit\n\n   -a, --global-one\n      \n\n   -b, --global-two\n      \n\nCommand Arguments:\n\n   [ARG ...]\n      arguments to pass after `exe fake`\n",
+			wantStdout: "Usage: exe [--help|-h] [--version|-v] [--timeout duration] [--color when] [--global-one|-a] [--global-two|-b] [--] alias [--] [ARG ...]\n\nalias for `exe fake`. see `exe fake --help` for detailed help page about fake\n\nGlobal Arguments:\n\n   -h, --help\n      print a help message and exit\n\n   -v, --version\n      print a version message and exit\n\n   --timeout duration\n      maximum time the command may run\n\n   --color when\n      when to use colors in output (choices: auto, always, never; default auto)\n\n   -a, --global-one\n      \n\n   -b, --global-two\n      \n\nCommand Arguments:\n\n   [ARG ...]\n      arguments to pass after `exe fake`\n",
 			wantCode:   0,
 		},
 
@@ -273,7 +296,7 @@ func TestProgram_Main(t *testing.T) {
 			desc:        iDescription{Requirements: reqAny},
 			positionals: makeTPM_Positionals[struct{}](),
 
-			wantStdout: "Usage: exe [--help|-h] [--version|-v] [--global-one|-a] [--global-two|-b] [--] alias [--] [ARG ...]\n\nsome useful alias\n\nalias for `exe fake something else`. see `exe fake --help` for detailed help page about fake\n\nGlobal Arguments:\n\n   -h, --help\n      print a help message and exit\n\n   -v, --version\n      print a version message and exit\n\n   -a, --global-one\n      \n\n   -b, --global-two\n      \n\nCommand Arguments:\n\n   [ARG ...]\n      arguments to pass after `exe fake something else`\n",
+			wantStdout: "Usage: exe [--help|-h] [--version|-v] [--timeout duration] [--color when] [--global-one|-a] [--global-two|-b] [--] alias [--] [ARG ...]\n\nsome useful alias\n\nalias for `exe fake something else`. see `exe fake --help` for detailed help page about fake\n\nGlobal Arguments:\n\n   -h, --help\n      print a help message and exit\n\n   -v, --version\n      print a version message and exit\n\n   --timeout duration\n      maximum time the command may run\n\n   --color when\n      when to use colors in output (choices: auto, always, never; default auto)\n\n   -a, --global-one\n      \n\n   -b, --global-two\n      \n\nCommand Arguments:\n\n   [ARG ...]\n      arguments to pass after `exe fake something else`\n",
 			wantCode:   0,
 		},
 
@@ -302,6 +325,19 @@ func TestProgram_Main(t *testing.T) {
 			wantCode:   4,
 		},
 
+		{
+			name: "'fake' with unknown argument (suggestion)",
+			args: []string{"fake", "--stdot", "message"},
+			desc: iDescription{Requirements: reqAny},
+			positionals: makeTPM_Positionals[struct {
+				Args []string
+			}](),
+
+			wantStdout: "",
+			wantStderr: "wrong arguments for fake: unknown flag `stdot', did you mean --stdout?\n",
+			wantCode:   4,
+		},
+
 		{
 			name: "'fake' with unknown argument (allowed)",
 			args: []string{"fake", "--", "--argument-not-declared"},
@@ -452,6 +488,30 @@ func TestProgram_Main(t *testing.T) {
 			wantCode:   2,
 		},
 
+		{
+			name:        "misspelled command",
+			args:        []string{"fkae"},
+			positionals: makeTPM_Positionals[struct{}](),
+
+			wantStderr: "unknown command \"fkae\": did you mean \"fake\"?\n",
+			wantCode:   2,
+		},
+
+		{
+			name: "misspelled alias",
+
+			alias: Alias{
+				Name:    "alias",
+				Command: "fake",
+			},
+
+			args:        []string{"alais"},
+			positionals: makeTPM_Positionals[struct{}](),
+
+			wantStderr: "unknown command \"alais\": did you mean \"alias\"?\n",
+			wantCode:   2,
+		},
+
 		{
 			name: "'notExistent' command (with alias)",
 
@@ -602,3 +662,126 @@ func TestProgram_Main(t *testing.T) {
 		})
 	}
 }
+
+//nolint:paralleltest // uses t.Setenv
+func TestProgram_Main_autoEnv(t *testing.T) {
+	t.Setenv("ENVEXE_GLOBAL_ONE", "from-env")
+	t.Setenv("ENVEXE_CMD_STDOUT", "stdout-from-env")
+
+	tests := []struct {
+		name    string
+		autoEnv bool
+		args    []string
+
+		wantStdout string
+	}{
+		{
+			name:       "environment ignored by default",
+			autoEnv:    false,
+			args:       []string{"cmd"},
+			wantStdout: "global: \"\", stdout: \"write to stdout\"\n",
+		},
+		{
+			name:       "environment overrides defaults",
+			autoEnv:    true,
+			args:       []string{"cmd"},
+			wantStdout: "global: \"from-env\", stdout: \"stdout-from-env\"\n",
+		},
+		{
+			name:       "arguments override environment",
+			autoEnv:    true,
+			args:       []string{"--global-one", "from-args", "cmd", "--stdout", "stdout-from-args"},
+			wantStdout: "global: \"from-args\", stdout: \"stdout-from-args\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdoutBuffer bytes.Buffer
+			var stderrBuffer bytes.Buffer
+			stream := stream.NewIOStream(&stdoutBuffer, &stderrBuffer, nil)
+
+			program := makeProgram()
+			program.Info.Executable = "envexe"
+			program.AutoEnv = tt.autoEnv
+			program.Register(&tCommand[struct{}]{
+				MDesc: iDescription{
+					Command:      "cmd",
+					Requirements: func(flag meta.Flag) bool { return true },
+				},
+				MAfterParse: func() error { return nil },
+				MRun: func(command tCommand[struct{}], context iContext) error {
+					_, err := context.Printf("global: %q, stdout: %q\n", context.Args.Flags.GlobalOne, command.StdoutMsg)
+					return err
+				},
+			})
+
+			code, _ := exit.CodeFromError(program.Main(stream, "", tt.args))
+			if code != 0 {
+				t.Errorf("Program.Main() code = %v, stderr = %q", code, stderrBuffer.String())
+			}
+			if gotStdout := stdoutBuffer.String(); gotStdout != tt.wantStdout {
+				t.Errorf("Program.Main() stdout = %q, wantStdout %q", gotStdout, tt.wantStdout)
+			}
+		})
+	}
+}
+
+//nolint:paralleltest // uses t.Setenv
+func TestProgram_Main_autoEnvCommands(t *testing.T) {
+	t.Setenv("ENVEXE_STDOUT", "ambiguous")
+	t.Setenv("ENVEXE_A_STDOUT", "from-a")
+	t.Setenv("ENVEXE_REPO_LIST_STDOUT", "from-repo-list")
+
+	program := makeProgram()
+	program.Info.Executable = "envexe"
+	program.AutoEnv = true
+	program.RegisterGroup(Group{Name: "repo"})
+	for _, name := range []string{"a", "b", "repo list"} {
+		program.Register(&tCommand[struct{}]{
+			MDesc: iDescription{
+				Command:      name,
+				Requirements: func(flag meta.Flag) bool { return true },
+			},
+			MAfterParse: func() error { return nil },
+			MRun: func(command tCommand[struct{}], context iContext) error {
+				_, err := context.Printf("stdout: %q\n", command.StdoutMsg)
+				return err
+			},
+		})
+	}
+
+	tests := []struct {
+		args []string
+
+		wantStdout string
+	}{
+		{[]string{"a"}, "stdout: \"from-a\"\n"},
+		{[]string{"b"}, "stdout: \"write to stdout\"\n"},
+		{[]string{"repo", "list"}, "stdout: \"from-repo-list\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			var stdoutBuffer bytes.Buffer
+			var stderrBuffer bytes.Buffer
+			stream := stream.NewIOStream(&stdoutBuffer, &stderrBuffer, nil)
+
+			code, _ := exit.CodeFromError(program.Main(stream, "", tt.args))
+			if code != 0 {
+				t.Errorf("Program.Main() code = %v, stderr = %q", code, stderrBuffer.String())
+			}
+			if gotStdout := stdoutBuffer.String(); gotStdout != tt.wantStdout {
+				t.Errorf("Program.Main() stdout = %q, wantStdout %q", gotStdout, tt.wantStdout)
+			}
+		})
+	}
+
+	t.Run("help", func(t *testing.T) {
+		var stdoutBuffer bytes.Buffer
+		stream := stream.NewIOStream(&stdoutBuffer, io.Discard, nil)
+
+		_ = program.Main(stream, "", []string{"repo", "list", "--help"})
+		if want := "env: ENVEXE_REPO_LIST_STDOUT"; !strings.Contains(stdoutBuffer.String(), want) {
+			t.Errorf("Program.Main() help = %q, does not contain %q", stdoutBuffer.String(), want)
+		}
+	})
+}
@@ -0,0 +1,310 @@
+//spellchecker:words goprogram
+package goprogram
+
+//spellchecker:words bufio context strconv strings github goprogram exit terminal pkglib stream
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.tkw01536.de/goprogram/exit"
+	"go.tkw01536.de/goprogram/terminal"
+	"go.tkw01536.de/pkglib/stream"
+)
+
+//spellchecker:words nolint wrapcheck
+
+// builtins recognized by REPL.
+const (
+	replHistory = "history"
+	replExit    = "exit"
+)
+
+var (
+	errREPLRead    = exit.NewErrorWithCode("unable to read input", exit.ExitGeneric)
+	errREPLQuote   = exit.NewErrorWithCode("unable to parse line: unterminated quote", exit.ExitGeneralArguments)
+	errREPLEscape  = exit.NewErrorWithCode("unable to parse line: trailing backslash", exit.ExitGeneralArguments)
+	errREPLHistory = exit.NewErrorWithCode("no such history entry", exit.ExitGeneralArguments)
+)
+
+// REPL runs an interactive read-eval-print loop for this program.
+//
+// REPL reads lines from the standard input of str until it is exhausted, or the "exit" builtin is invoked.
+// Before each line, a prompt is written to standard error.
+// Each line is split into arguments using shell-style quoting:
+// Single quotes preserve every character, double quotes allow escaping double quotes and backslashes, and "#" starts a comment.
+// The arguments are then executed like those passed to Main, including expansion of keywords, aliases and groups.
+// Errors are printed to standard error, but do not stop the loop.
+// Errors are styled according to the "--color" flag of the most recent command, in the same way as by Main.
+//
+// Unlike Main, the context (see NewContext) and configuration file (see ConfigFile) are set up only once.
+// The environment is created using the context of the first command requiring it, and then reused by all further commands.
+// When creating the environment fails, creating it is attempted again for the next command.
+//
+// Lines are recorded in a history.
+// The "history" builtin prints the history, a line starting with "!!" repeats the previous line,
+// and a line starting with "!n" repeats the n-th line, in each case followed by the remainder of the line.
+// Builtins are only recognized when no keyword, alias, group or command of the same name exists.
+//
+// Once all lines have been executed, REPL prints a summary to standard error.
+// It returns nil if every command succeeded.
+// Otherwise, it returns an error counting failed commands, using the exit code of the last failure.
+func (p Program[E, P, F, R]) REPL(str stream.IOStream, params P) (err error) {
+	// whenever an error occurs, we want it printed
+	var color terminal.ColorMode
+	defer func() {
+		err = exit.DieStyled(str, err, color.Enabled(str.Stderr))
+	}()
+
+	// create a new context
+	context := Context[E, P, F, R]{
+		Context:  context.Background(),
+		IOStream: str,
+		Program:  p,
+	}
+	defer context.handleCleanup()()
+
+	if err := p.initContextContext(&params, &context); err != nil {
+		return err
+	}
+	if context.config, err = p.readConfig(""); err != nil {
+		return err
+	}
+
+	// create the environment only once
+	var (
+		environment    E
+		hasEnvironment bool
+	)
+	setupEnvironment := func(context Context[E, P, F, R]) (E, error) {
+		if !hasEnvironment {
+			e, err := p.makeEnvironment(params, context)
+			if err != nil {
+				return e, err
+			}
+			environment, hasEnvironment = e, true
+		}
+		return environment, nil
+	}
+
+	var (
+		history  []string
+		total    int
+		failed   int
+		lastCode exit.ExitCode
+	)
+
+	lines := bufio.NewScanner(strings.NewReader(""))
+	if str.Stdin != nil {
+		lines = bufio.NewScanner(str.Stdin)
+	}
+	for {
+		// check that the context isn't closed!
+		if err := closedContextError(context.Context); err != nil {
+			return err
+		}
+
+		_, _ = str.EPrintf("%s> ", p.Info.Executable) // no way to report the failure
+		if !lines.Scan() {
+			break
+		}
+
+		line := strings.TrimSpace(lines.Text())
+		if line == "" {
+			continue
+		}
+
+		// expand and record history
+		expanded, err := expandHistory(line, history)
+		if err != nil {
+			_ = exit.DieStyled(str, err, color.Enabled(str.Stderr))
+			continue
+		}
+		if expanded != line {
+			_, _ = str.EPrintln(expanded)
+		}
+		history = append(history, expanded)
+
+		args, err := splitLine(expanded)
+		if len(args) == 0 && err == nil {
+			continue // only a comment
+		}
+
+		// handle builtins
+		if err == nil && len(args) == 1 && !p.hasName(args[0]) {
+			switch args[0] {
+			case replExit:
+				return replSummary(str, total, failed, lastCode)
+			case replHistory:
+				for i, entry := range history {
+					if _, err := context.Printf("%5d  %s\n", i+1, entry); err != nil {
+						return fmt.Errorf("%w: %w", errProgramIO, err)
+					}
+				}
+				continue
+			}
+		}
+
+		// run the command
+		lineContext := context
+		if err == nil {
+			err = p.replRun(&lineContext, args, setupEnvironment)
+		}
+
+		// style errors as requested by the command
+		if mode := lineContext.Args.Universals.Color; mode != "" {
+			color = mode
+		}
+
+		total++
+		if err != nil {
+			failed++
+			lastCode, _ = exit.CodeFromError(exit.DieStyled(str, err, color.Enabled(str.Stderr)))
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return fmt.Errorf("%w: %w", errREPLRead, err)
+	}
+
+	return replSummary(str, total, failed, lastCode)
+}
+
+// replSummary returns the error to be returned by REPL after running total commands, see runSummary.
+// When every command succeeded, it instead prints a summary to the standard error of str.
+func replSummary(str stream.IOStream, total, failed int, lastCode exit.ExitCode) error {
+	if err := runSummary("", total, failed, lastCode); err != nil {
+		return err
+	}
+	_, _ = str.EPrintf("%d of %d commands succeeded\n", total, total) // no way to report the failure
+	return nil
+}
+
+// replRun runs a single command of REPL in the given context.
+// The arguments are parsed into the context, so that the caller can honour the flags of the command.
+//
+//nolint:wrapcheck
+func (p Program[E, P, F, R]) replRun(context *Context[E, P, F, R], args []string, setupEnvironment func(context Context[E, P, F, R]) (E, error)) error {
+	if err := p.parseProgramFlags(&context.Args, args, context.config); err != nil {
+		return err
+	}
+	return p.run(*context, setupEnvironment)
+}
+
+// runSummary returns the error to be returned after running total commands, of which failed returned an error.
+// The error uses the exit code of the last failed command.
+// When no command failed, returns nil.
+func runSummary(prefix string, total, failed int, lastCode exit.ExitCode) error {
+	if failed == 0 {
+		return nil
+	}
+	return exit.NewErrorWithCode(fmt.Sprintf("%s%d of %d commands failed", prefix, failed, total), lastCode)
+}
+
+// hasName checks if a keyword, alias, group or command with the given name exists.
+func (p Program[E, P, F, R]) hasName(name string) bool {
+	_, isKeyword := p.keywords[name]
+	_, isAlias := p.aliases[name]
+	_, isGroup := p.groups[name]
+	_, isCommand := p.commands[name]
+	return isKeyword || isAlias || isGroup || isCommand
+}
+
+// expandHistory expands a reference to the history at the start of line.
+// "!!" refers to the last entry of history, "!n" to the n-th entry (starting at 1).
+// When line does not start with "!", it is returned unchanged.
+func expandHistory(line string, history []string) (string, error) {
+	if !strings.HasPrefix(line, "!") {
+		return line, nil
+	}
+
+	reference, rest, _ := strings.Cut(line, " ")
+	index := len(history)
+	if reference != "!!" {
+		var err error
+		if index, err = strconv.Atoi(reference[1:]); err != nil {
+			return "", fmt.Errorf("%w: %q", errREPLHistory, reference)
+		}
+	}
+	if index < 1 || index > len(history) {
+		return "", fmt.Errorf("%w: %q", errREPLHistory, reference)
+	}
+
+	if rest == "" {
+		return history[index-1], nil
+	}
+	return history[index-1] + " " + rest, nil
+}
+
+// splitLine splits line into arguments using shell-style quoting.
+//
+// Arguments are separated by unquoted whitespace.
+// Inside single quotes, every character is taken literally.
+// Inside double quotes, a backslash escapes a following double quote or backslash.
+// Outside of quotes, a backslash escapes any following character.
+// An unquoted "#" at the start of an argument starts a comment extending to the end of the line.
+//
+// When a quote is not terminated, or line ends with a backslash, returns an error of type exit.Error.
+func splitLine(line string) (args []string, err error) {
+	var (
+		current strings.Builder
+		inWord  bool // are we currently inside an argument?
+		quote   rune // the quote we are currently inside of, or 0
+		escape  bool // was the previous character an (active) backslash?
+	)
+
+scan:
+	for _, r := range line {
+		switch {
+		case escape:
+			escape = false
+			if quote == '"' && r != '"' && r != '\\' {
+				current.WriteRune('\\')
+			}
+			current.WriteRune(r)
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+				continue
+			}
+			current.WriteRune(r)
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escape = true
+			default:
+				current.WriteRune(r)
+			}
+		case r == '\\':
+			escape = true
+			inWord = true
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				args = append(args, current.String())
+				current.Reset()
+				inWord = false
+			}
+		case r == '#' && !inWord:
+			break scan // comment until the end of the line
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	switch {
+	case quote != 0:
+		return nil, errREPLQuote
+	case escape:
+		return nil, errREPLEscape
+	case inWord:
+		args = append(args, current.String())
+	}
+	return args, nil
+}
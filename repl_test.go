@@ -0,0 +1,139 @@
+//spellchecker:words goprogram
+package goprogram //nolint:testpackage // tests internal behavior
+
+//spellchecker:words bytes errors reflect strings testing github goprogram exit pkglib stream
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.tkw01536.de/goprogram/exit"
+	"go.tkw01536.de/pkglib/stream"
+)
+
+func TestProgram_REPL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+
+		wantStdout       string
+		wantStderr       string
+		wantCode         uint8
+		wantEnvironments int
+	}{
+		{
+			name:             "commands and aliases",
+			input:            "echo hello 'big world'\n\n# a comment\nsay \"x \\\"y\\\"\"\n",
+			wantStdout:       "[hello big world]\n[hello x \"y\"]\n",
+			wantStderr:       "exe> exe> exe> exe> exe> 2 of 2 commands succeeded\n",
+			wantEnvironments: 1,
+		},
+		{
+			name:             "history",
+			input:            "echo a\necho b\nhistory\n!1 c\n!!\n!9\n",
+			wantStdout:       "[a]\n[b]\n    1  echo a\n    2  echo b\n    3  history\n[a c]\n[a c]\n",
+			wantStderr:       "exe> exe> exe> exe> echo a c\nexe> echo a c\nexe> no such history entry: \"!9\"\nexe> 4 of 4 commands succeeded\n",
+			wantEnvironments: 1,
+		},
+		{
+			name:             "continue after errors",
+			input:            "echo a\nunknown\necho 'b\necho c\n",
+			wantStdout:       "[a]\n[c]\n",
+			wantStderr:       "exe> exe> unknown command: must be one of \"echo\"\nexe> unable to parse line: unterminated quote\nexe> exe> 2 of 4 commands failed\n",
+			wantCode:         3,
+			wantEnvironments: 1,
+		},
+		{
+			name:             "styled errors",
+			input:            "--color=always unknown\nunknown\n",
+			wantStdout:       "",
+			wantStderr:       "exe> \x1b[1;31munknown command\x1b[0m: must be one of \"echo\"\nexe> unknown command: must be one of \"echo\"\nexe> 2 of 2 commands failed\n",
+			wantCode:         2,
+			wantEnvironments: 0,
+		},
+		{
+			name:             "styled summary",
+			input:            "unknown\n--color=always unknown\n",
+			wantStdout:       "",
+			wantStderr:       "exe> unknown command: must be one of \"echo\"\nexe> \x1b[1;31munknown command\x1b[0m: must be one of \"echo\"\nexe> \x1b[1;31m2 of 2 commands failed\x1b[0m\n",
+			wantCode:         2,
+			wantEnvironments: 0,
+		},
+		{
+			name:             "exit",
+			input:            "unknown\nexit\necho a\n",
+			wantStdout:       "",
+			wantStderr:       "exe> unknown command: must be one of \"echo\"\nexe> 1 of 1 commands failed\n",
+			wantCode:         2,
+			wantEnvironments: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdoutBuffer bytes.Buffer
+			var stderrBuffer bytes.Buffer
+			stream := stream.NewIOStream(&stdoutBuffer, &stderrBuffer, strings.NewReader(tt.input))
+
+			program := makeProgram()
+			environments := 0
+			program.NewEnvironment = func(params tParameters, context iContext) (tEnvironment, error) {
+				environments++
+				return "", nil
+			}
+			program.Register(makeEchoCommand("echo"))
+			program.RegisterAlias(Alias{Name: "say", Command: "echo", Args: []string{"hello"}})
+
+			code, _ := exit.CodeFromError(program.REPL(stream, ""))
+
+			if gotCode := uint8(code); gotCode != tt.wantCode {
+				t.Errorf("Program.REPL() code = %v, wantCode %v", gotCode, tt.wantCode)
+			}
+			if gotStdout := stdoutBuffer.String(); gotStdout != tt.wantStdout {
+				t.Errorf("Program.REPL() stdout = %q, wantStdout %q", gotStdout, tt.wantStdout)
+			}
+			if gotStderr := stderrBuffer.String(); gotStderr != tt.wantStderr {
+				t.Errorf("Program.REPL() stderr = %q, wantStderr %q", gotStderr, tt.wantStderr)
+			}
+			if environments != tt.wantEnvironments {
+				t.Errorf("Program.REPL() created %d environments, want %d", environments, tt.wantEnvironments)
+			}
+		})
+	}
+}
+
+func Test_splitLine(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		line    string
+		want    []string
+		wantErr error
+	}{
+		{"", nil, nil},
+		{"  a  b\tc ", []string{"a", "b", "c"}, nil},
+		{`a 'b c' "d e"`, []string{"a", "b c", "d e"}, nil},
+		{`'a "b"' "c 'd'"`, []string{`a "b"`, `c 'd'`}, nil},
+		{`"a \"b\" \\ \c"`, []string{`a "b" \ \c`}, nil},
+		{`a\ b \'c`, []string{"a b", "'c"}, nil},
+		{`a''b "" ''`, []string{"ab", "", ""}, nil},
+		{"a # b c", []string{"a"}, nil},
+		{"a#b", []string{"a#b"}, nil},
+		{"'a b", nil, errREPLQuote},
+		{`a \`, nil, errREPLEscape},
+	}
+	for _, tt := range tests {
+		got, err := splitLine(tt.line)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("splitLine(%q) error = %v, wantErr %v", tt.line, err, tt.wantErr)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitLine(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+//spellchecker:words goprogram
+package goprogram
+
+//spellchecker:words bufio strings github goprogram exit
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.tkw01536.de/goprogram/exit"
+)
+
+// directives recognized by RunScript.
+const (
+	scriptSet             = "set"
+	scriptStopOnError     = "-e"
+	scriptContinueOnError = "+e"
+)
+
+var errScriptRead = exit.NewErrorWithCode("unable to read script", exit.ExitGeneric)
+
+// ScriptResult is the result of running a single line of a script, see RunScript.
+type ScriptResult struct {
+	Line int           // number of the (first) line of the command, starting at 1
+	Args []string      // arguments the line was split into
+	Code exit.ExitCode // exit code of the command
+}
+
+// RunScript runs the commands of the script file at path from within a given context.
+// When path is "-", the script is read from the standard input of context.
+//
+// Each line of the script is split into arguments in the same way as by REPL, and then executed like the arguments passed to Main.
+// Empty lines and comments are ignored, and a line ending in a backslash is continued on the next line.
+// Like Exec, RunScript does not create a new environment; every command uses the environment of context.
+//
+// By default, RunScript continues with the next line when a command fails.
+// A line "set -e" causes RunScript to stop at the first failing command, and "set +e" restores the default.
+// These directives are only recognized when no keyword, alias, group or command named "set" exists.
+//
+// The error of each failing command is printed to standard error, prefixed with path and line number.
+// It is styled according to the "--color" flag of context.
+// RunScript returns the result of each command that was run.
+// The returned error is nil if every command succeeded.
+// Otherwise, it counts failed commands and uses the exit code of the last failure.
+func (p Program[E, P, F, R]) RunScript(context Context[E, P, F, R], path string) (results []ScriptResult, err error) {
+	var script io.Reader = context.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errScriptRead, err)
+		}
+		defer func() {
+			_ = file.Close() // file was only read
+		}()
+		script = file
+	}
+	if script == nil {
+		script = strings.NewReader("")
+	}
+
+	var (
+		stopOnError bool
+		failed      int
+		lastCode    exit.ExitCode
+	)
+
+	lines := bufio.NewScanner(script)
+	number := 0
+	for lines.Scan() {
+		number++
+		start := number
+
+		// join continued lines
+		line := lines.Text()
+		for strings.HasSuffix(line, `\`) && lines.Scan() {
+			number++
+			line = line[:len(line)-1] + "\n" + lines.Text()
+		}
+
+		args, err := splitLine(line)
+		if err == nil && len(args) == 0 {
+			continue // empty line or comment
+		}
+
+		// handle directives
+		if err == nil && len(args) == 2 && args[0] == scriptSet && !p.hasName(scriptSet) {
+			switch args[1] {
+			case scriptStopOnError:
+				stopOnError = true
+				continue
+			case scriptContinueOnError:
+				stopOnError = false
+				continue
+			}
+		}
+
+		// run the command
+		if err == nil {
+			err = p.scriptRun(context, args)
+		}
+
+		result := ScriptResult{Line: start, Args: args}
+		if err != nil {
+			failed++
+			result.Code, _ = exit.CodeFromError(exit.DieStyled(context.IOStream, fmt.Errorf("%s:%d: %w", path, start, err), context.Args.Universals.Color.Enabled(context.Stderr)))
+			lastCode = result.Code
+		}
+		results = append(results, result)
+
+		if err != nil && stopOnError {
+			return results, runSummary(fmt.Sprintf("%s: stopped at line %d: ", path, start), len(results), failed, lastCode)
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return results, fmt.Errorf("%w: %w", errScriptRead, err)
+	}
+
+	return results, runSummary(path+": ", len(results), failed, lastCode)
+}
+
+// scriptRun runs a single command of a script from within the given context.
+//
+//nolint:wrapcheck
+func (p Program[E, P, F, R]) scriptRun(context Context[E, P, F, R], args []string) error {
+	lineContext := Context[E, P, F, R]{
+		IOStream: context.IOStream,
+		Context:  context.Context,
+		Program:  p,
+
+		config: context.config,
+		inExec: true,
+	}
+	if err := p.parseProgramFlags(&lineContext.Args, args, lineContext.config); err != nil {
+		return err
+	}
+	return p.run(lineContext, func(Context[E, P, F, R]) (E, error) { return context.Environment, nil })
+}
@@ -0,0 +1,124 @@
+//spellchecker:words goprogram
+package goprogram //nolint:testpackage // tests internal behavior
+
+//spellchecker:words bytes path filepath strings testing github goprogram exit meta pkglib stream
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.tkw01536.de/goprogram/exit"
+	"go.tkw01536.de/goprogram/meta"
+	"go.tkw01536.de/pkglib/stream"
+)
+
+type runScriptStruct = struct {
+	File string `description:"script to run" required:"1-1"`
+}
+
+// makeRunScriptCommand makes a command that runs a script and prints the result of each line.
+func makeRunScriptCommand() iCommand {
+	return &tCommand[runScriptStruct]{
+		MDesc: iDescription{
+			Command:      "run-script",
+			Requirements: func(flag meta.Flag) bool { return true },
+		},
+		MAfterParse: func() error { return nil },
+		MRun: func(command tCommand[runScriptStruct], context iContext) error {
+			results, err := context.RunScript(command.Positionals.File)
+			for _, result := range results {
+				if _, err := context.Printf("line %d: %v exited %d\n", result.Line, result.Args, result.Code); err != nil {
+					return fmt.Errorf("failed to write output: %w", err)
+				}
+			}
+			return err
+		},
+	}
+}
+
+func TestProgram_RunScript(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		color  string
+		script string
+
+		wantStdout string
+		wantStderr string // "${file}" is replaced by the path to the script
+		wantCode   uint8
+	}{
+		{
+			name:       "all lines succeed",
+			script:     "# a comment\necho a\n\n--global-one value echo 'b c'\necho d \\\n  e\n",
+			wantStdout: "[a]\n[b c]\n[d e]\nline 2: [echo a] exited 0\nline 4: [--global-one value echo b c] exited 0\nline 5: [echo d e] exited 0\n",
+		},
+		{
+			name:       "continue on error",
+			script:     "echo a\nunknown\n--not-a-flag echo b\necho c\n",
+			wantStdout: "[a]\n[c]\nline 1: [echo a] exited 0\nline 2: [unknown] exited 2\nline 3: [--not-a-flag echo b] exited 3\nline 4: [echo c] exited 0\n",
+			wantStderr: "${file}:2: unknown command: must be one of \"echo\", \"run-script\"\n${file}:3: unable to parse arguments: unknown flag `not-a-flag'\n${file}: 2 of 4 commands failed\n",
+			wantCode:   3,
+		},
+		{
+			name:       "stop on error",
+			script:     "echo a\nset -e\nunknown\necho b\n",
+			wantStdout: "[a]\nline 1: [echo a] exited 0\nline 3: [unknown] exited 2\n",
+			wantStderr: "${file}:3: unknown command: must be one of \"echo\", \"run-script\"\n${file}: stopped at line 3: 1 of 2 commands failed\n",
+			wantCode:   2,
+		},
+		{
+			name:       "styled errors",
+			color:      "always",
+			script:     "unknown\n",
+			wantStdout: "line 1: [unknown] exited 2\n",
+			wantStderr: "\x1b[1;31m${file}\x1b[0m:1: unknown command: must be one of \"echo\", \"run-script\"\n\x1b[1;31m${file}\x1b[0m: 1 of 1 commands failed\n",
+			wantCode:   2,
+		},
+		{
+			name:       "stop on error can be disabled",
+			script:     "set -e\nset +e\n'unterminated\necho a\n",
+			wantStdout: "[a]\nline 3: [] exited 3\nline 4: [echo a] exited 0\n",
+			wantStderr: "${file}:3: unable to parse line: unterminated quote\n${file}: 1 of 2 commands failed\n",
+			wantCode:   3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "script.exe")
+			if err := os.WriteFile(path, []byte(tt.script), 0o600); err != nil {
+				t.Fatalf("failed to write script: %v", err)
+			}
+
+			var stdoutBuffer bytes.Buffer
+			var stderrBuffer bytes.Buffer
+			stream := stream.NewIOStream(&stdoutBuffer, &stderrBuffer, nil)
+
+			program := makeProgram()
+			program.Register(makeEchoCommand("echo"))
+			program.Register(makeRunScriptCommand())
+
+			args := []string{"run-script", path}
+			if tt.color != "" {
+				args = append([]string{"--color", tt.color}, args...)
+			}
+
+			code, _ := exit.CodeFromError(program.Main(stream, "", args))
+
+			if gotCode := uint8(code); gotCode != tt.wantCode {
+				t.Errorf("Program.RunScript() code = %v, wantCode %v", gotCode, tt.wantCode)
+			}
+			if gotStdout := stdoutBuffer.String(); gotStdout != tt.wantStdout {
+				t.Errorf("Program.RunScript() stdout = %q, wantStdout %q", gotStdout, tt.wantStdout)
+			}
+			if gotStderr, wantStderr := stderrBuffer.String(), strings.ReplaceAll(tt.wantStderr, "${file}", path); gotStderr != wantStderr {
+				t.Errorf("Program.RunScript() stderr = %q, wantStderr %q", gotStderr, wantStderr)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+//spellchecker:words goprogram
+package goprogram
+
+//spellchecker:words slices strings
+import (
+	"slices"
+	"strings"
+)
+
+//spellchecker:words levenshtein damerau
+
+// maxSuggestions is the maximum number of suggestions returned by suggest.
+const maxSuggestions = 3
+
+// suggest returns those candidates that are most similar to name, in sorted order.
+//
+// Similarity is measured using the edit distance between name and each candidate.
+// Only the candidates closest to name are returned, and only if they are not too far away from it.
+func suggest(name string, candidates []string) []string {
+	// allow roughly one edit for every three characters, but never replace the entire name
+	limit := max(1, len(name)/3)
+	if limit >= len(name) {
+		limit = len(name) - 1
+	}
+
+	type suggestion struct {
+		candidate string
+		distance  int
+	}
+
+	// consider each candidate only once, in a deterministic order
+	candidates = slices.Compact(slices.Sorted(slices.Values(candidates)))
+
+	var suggestions []suggestion
+	for _, candidate := range candidates {
+		distance := editDistance(name, candidate)
+		if distance > limit {
+			continue
+		}
+		suggestions = append(suggestions, suggestion{candidate: candidate, distance: distance})
+	}
+
+	// only keep the closest suggestions
+	best := limit
+	for _, s := range suggestions {
+		best = min(best, s.distance)
+	}
+
+	results := make([]string, 0, min(len(suggestions), maxSuggestions))
+	for _, s := range suggestions {
+		if s.distance != best || len(results) == maxSuggestions {
+			continue
+		}
+		results = append(results, s.candidate)
+	}
+	return results
+}
+
+// editDistance returns the edit distance between a and b.
+// It counts insertions, deletions, substitutions and transpositions of adjacent characters as a single edit each.
+//
+// This is the optimal string alignment variant of the Damerau-Levenshtein distance.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	// d[i][j] holds the distance between ra[:i] and rb[:j]
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = min(
+				d[i-1][j]+1,      // deletion
+				d[i][j-1]+1,      // insertion
+				d[i-1][j-1]+cost, // substitution
+			)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1) // transposition
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}
+
+// fmtSuggestions formats a list of suggestions for use in an error message.
+// It is of the form "a", "a or b" or "a, b or c".
+func fmtSuggestions(suggestions []string) string {
+	if len(suggestions) < 2 {
+		return strings.Join(suggestions, "")
+	}
+	return strings.Join(suggestions[:len(suggestions)-1], ", ") + " or " + suggestions[len(suggestions)-1]
+}
@@ -0,0 +1,89 @@
+//spellchecker:words goprogram
+package goprogram //nolint:testpackage
+
+//spellchecker:words reflect testing
+import (
+	"reflect"
+	"testing"
+)
+
+//spellchecker:words nolint testpackage stauts
+
+func Test_editDistance(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"", "abc", 3},
+		{"abc", "", 3},
+		{"status", "status", 0},
+		{"status", "stats", 1},
+		{"stauts", "status", 1},
+		{"kitten", "sitting", 3},
+		{"global-on", "global-one", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			t.Parallel()
+
+			if got := editDistance(tt.a, tt.b); got != tt.want {
+				t.Errorf("editDistance() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_suggest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		input      string
+		candidates []string
+		want       []string
+	}{
+		{"no candidates", "status", nil, []string{}},
+		{"transposition", "stauts", []string{"status", "fetch"}, []string{"status"}},
+		{"closest only", "stat", []string{"status", "stats", "start"}, []string{"start", "stats"}},
+		{"closest only (2)", "global-on", []string{"global-two", "global-one"}, []string{"global-one"}},
+		{"duplicate candidates", "stat", []string{"stats", "stats"}, []string{"stats"}},
+		{"nothing similar", "notExistent", []string{"fake"}, []string{}},
+		{"single character", "x", []string{"a", "b"}, []string{}},
+		{"at most three", "ab", []string{"ac", "ad", "ae", "af"}, []string{"ac", "ad", "ae"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := suggest(tt.input, tt.candidates); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("suggest() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_fmtSuggestions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		suggestions []string
+		want        string
+	}{
+		{nil, ""},
+		{[]string{"a"}, "a"},
+		{[]string{"a", "b"}, "a or b"},
+		{[]string{"a", "b", "c"}, "a, b or c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			t.Parallel()
+
+			if got := fmtSuggestions(tt.suggestions); got != tt.want {
+				t.Errorf("fmtSuggestions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
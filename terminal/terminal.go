@@ -0,0 +1,113 @@
+// Package terminal provides facilities for writing output to terminals.
+//
+// It detects if output is written to a terminal, and implements optional styling of output using ANSI escape sequences.
+//
+//spellchecker:words terminal
+package terminal
+
+//spellchecker:words strings
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+//spellchecker:words CLICOLOR
+
+// IsTerminal reports whether w refers to a terminal.
+func IsTerminal(w io.Writer) bool {
+	_, ok := Width(w)
+	return ok
+}
+
+// ColorMode determines when output is styled.
+type ColorMode string
+
+const (
+	// ColorAuto styles output written to a terminal, unless overridden by the environment.
+	ColorAuto ColorMode = "auto"
+	// ColorAlways always styles output.
+	ColorAlways ColorMode = "always"
+	// ColorNever never styles output.
+	ColorNever ColorMode = "never"
+)
+
+// Enabled reports whether output written to w should be styled in this mode.
+//
+// In ColorAuto mode (and for unknown modes), output is not styled when the "NO_COLOR" environment variable is non-empty.
+// Otherwise, output is styled when "CLICOLOR_FORCE" is set to a non-empty value other than "0", or when w is a terminal.
+func (mode ColorMode) Enabled(w io.Writer) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	case ColorAuto:
+	}
+
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if force := os.Getenv("CLICOLOR_FORCE"); force != "" && force != "0" {
+		return true
+	}
+	return IsTerminal(w)
+}
+
+// Style selects a graphic rendition of text.
+// It holds the parameters of an ANSI "Select Graphic Rendition" escape sequence, such as "1" for bold text.
+type Style string
+
+// Styles used by this module.
+const (
+	Bold      Style = "1"
+	Underline Style = "4"
+	Green     Style = "32"
+	Cyan      Style = "36"
+	BoldRed   Style = "1;31"
+)
+
+const (
+	escapeStart = "\x1b["
+	escapeEnd   = 'm'
+	reset       = escapeStart + "0m"
+)
+
+// Apply returns text rendered in this style.
+// Empty text, or text with an empty style, is returned unchanged.
+func (style Style) Apply(text string) string {
+	if style == "" || text == "" {
+		return text
+	}
+	return escapeStart + string(style) + string(escapeEnd) + text + reset
+}
+
+// ApplyIf is like Apply, but returns text unchanged when enabled is false.
+func (style Style) ApplyIf(text string, enabled bool) string {
+	if !enabled {
+		return text
+	}
+	return style.Apply(text)
+}
+
+// Strip removes all styles from text.
+func Strip(text string) string {
+	if !strings.Contains(text, escapeStart) {
+		return text
+	}
+
+	var builder strings.Builder
+	for {
+		before, after, found := strings.Cut(text, escapeStart)
+		builder.WriteString(before)
+		if !found {
+			return builder.String()
+		}
+
+		end := strings.IndexByte(after, escapeEnd)
+		if end < 0 {
+			return builder.String()
+		}
+		text = after[end+1:]
+	}
+}
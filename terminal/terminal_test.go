@@ -0,0 +1,94 @@
+//spellchecker:words terminal
+package terminal_test
+
+//spellchecker:words bytes testing github goprogram terminal
+import (
+	"bytes"
+	"testing"
+
+	"go.tkw01536.de/goprogram/terminal"
+)
+
+//spellchecker:words CLICOLOR
+
+func TestStyle_Apply(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		style terminal.Style
+		text  string
+		want  string
+	}{
+		{"bold text", terminal.Bold, "hello", "\x1b[1mhello\x1b[0m"},
+		{"combined style", terminal.BoldRed, "error", "\x1b[1;31merror\x1b[0m"},
+		{"empty text", terminal.Bold, "", ""},
+		{"empty style", "", "hello", "hello"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.style.Apply(tt.text); got != tt.want {
+				t.Errorf("Style.Apply() = %q, want %q", got, tt.want)
+			}
+			if got := tt.style.ApplyIf(tt.text, false); got != tt.text {
+				t.Errorf("Style.ApplyIf(false) = %q, want %q", got, tt.text)
+			}
+			if got := terminal.Strip(tt.want); got != tt.text {
+				t.Errorf("Strip() = %q, want %q", got, tt.text)
+			}
+		})
+	}
+}
+
+func TestStrip(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"plain text", "[--flag value]", "[--flag value]"},
+		{"styled text", "[\x1b[36m--flag\x1b[0m \x1b[4mvalue\x1b[0m]", "[--flag value]"},
+		{"unterminated sequence", "text\x1b[1", "text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := terminal.Strip(tt.text); got != tt.want {
+				t.Errorf("Strip() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColorMode_Enabled(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       terminal.ColorMode
+		noColor    string
+		forceColor string
+		want       bool
+	}{
+		{"always", terminal.ColorAlways, "1", "", true},
+		{"never", terminal.ColorNever, "", "1", false},
+		{"auto without terminal", terminal.ColorAuto, "", "", false},
+		{"auto with CLICOLOR_FORCE", terminal.ColorAuto, "", "1", true},
+		{"auto with CLICOLOR_FORCE=0", terminal.ColorAuto, "", "0", false},
+		{"auto with NO_COLOR", terminal.ColorAuto, "1", "1", false},
+		{"empty mode with CLICOLOR_FORCE", "", "", "1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.noColor)
+			t.Setenv("CLICOLOR_FORCE", tt.forceColor)
+
+			if got := tt.mode.Enabled(&bytes.Buffer{}); got != tt.want {
+				t.Errorf("ColorMode.Enabled() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+//spellchecker:words terminal
+package terminal
+
+import "io"
+
+// Width returns the number of columns of the terminal w refers to.
+// Terminals are not detected on this platform, hence it always returns false.
+func Width(w io.Writer) (int, bool) {
+	return 0, false
+}
@@ -0,0 +1,27 @@
+//go:build unix
+
+//spellchecker:words terminal
+package terminal
+
+//spellchecker:words golang
+import (
+	"io"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// Width returns the number of columns of the terminal w refers to.
+// If w is not a terminal, returns false.
+func Width(w io.Writer) (int, bool) {
+	file, ok := w.(*os.File)
+	if !ok {
+		return 0, false
+	}
+
+	size, err := unix.IoctlGetWinsize(int(file.Fd()), unix.TIOCGWINSZ) //nolint:gosec // file descriptors fit into an int
+	if err != nil {
+		return 0, false
+	}
+	return int(size.Col), true
+}
@@ -0,0 +1,51 @@
+//spellchecker:words goprogram
+package goprogram
+
+//spellchecker:words context errors time github goprogram exit
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.tkw01536.de/goprogram/exit"
+)
+
+//spellchecker:words nolint wrapcheck
+
+// Timeout returns the time limit for running the command of this context, or zero if there is none.
+//
+// A positive value of the universal "--timeout" flag takes precedence over the Timeout of the command's description.
+// A negative value disables the time limit.
+func (context Context[E, P, F, R]) Timeout() time.Duration {
+	switch timeout := context.Args.Universals.Timeout; {
+	case timeout > 0:
+		return timeout
+	case timeout < 0:
+		return 0
+	default:
+		return context.Description.Timeout
+	}
+}
+
+// runWithTimeout runs command within the time limit of runContext, see Context.Timeout.
+//
+//nolint:wrapcheck
+func (p Program[E, P, F, R]) runWithTimeout(runContext Context[E, P, F, R], command Command[E, P, F, R]) error {
+	timeout := runContext.Timeout()
+	if timeout <= 0 {
+		return p.runCommand(runContext, command)
+	}
+
+	limit := exit.NewErrorWithCode(fmt.Sprintf("%q exceeded time limit of %s", runContext.Description.Command, timeout), exit.ExitTimeout)
+
+	ctx, cancel := context.WithTimeoutCause(runContext.Context, timeout, limit)
+	defer cancel()
+	runContext.Context = ctx
+
+	err := p.runCommand(runContext, command)
+	if err != nil && errors.Is(context.Cause(ctx), limit) {
+		return fmt.Errorf("%w: %w", limit, err)
+	}
+	return err
+}
@@ -0,0 +1,113 @@
+//spellchecker:words goprogram
+package goprogram //nolint:testpackage // tests internal behavior
+
+//spellchecker:words bytes testing time github goprogram exit meta pkglib stream
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.tkw01536.de/goprogram/exit"
+	"go.tkw01536.de/goprogram/meta"
+	"go.tkw01536.de/pkglib/stream"
+)
+
+func TestProgram_Main_timeout(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		args    []string
+
+		wantStdout string
+		wantStderr string
+		wantCode   uint8
+	}{
+		{
+			name:       "no timeout",
+			args:       []string{"wait"},
+			wantStdout: "no deadline\n",
+		},
+		{
+			name:       "timeout from description",
+			timeout:    10 * time.Millisecond,
+			args:       []string{"wait"},
+			wantStderr: "\"wait\" exceeded time limit of 10ms: interrupted: context deadline exceeded\n",
+			wantCode:   124,
+		},
+		{
+			name:       "timeout from flag",
+			args:       []string{"--timeout", "20ms", "wait"},
+			wantStderr: "\"wait\" exceeded time limit of 20ms: interrupted: context deadline exceeded\n",
+			wantCode:   124,
+		},
+		{
+			name:       "flag overrides description",
+			timeout:    time.Hour,
+			args:       []string{"--timeout", "10ms", "wait"},
+			wantStderr: "\"wait\" exceeded time limit of 10ms: interrupted: context deadline exceeded\n",
+			wantCode:   124,
+		},
+		{
+			name:       "negative flag disables timeout",
+			timeout:    10 * time.Millisecond,
+			args:       []string{"--timeout", "-1s", "wait"},
+			wantStdout: "no deadline\n",
+		},
+		{
+			name:       "command finishing in time",
+			timeout:    time.Hour,
+			args:       []string{"--timeout", "1h", "wait", "done"},
+			wantStdout: "done\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdoutBuffer bytes.Buffer
+			var stderrBuffer bytes.Buffer
+			str := stream.NewIOStream(&stdoutBuffer, &stderrBuffer, nil)
+
+			program := makeProgram()
+			program.Register(&tCommand[echoStruct]{
+				MDesc: iDescription{
+					Command:      "wait",
+					Requirements: func(flag meta.Flag) bool { return true },
+					Timeout:      tt.timeout,
+				},
+				MAfterParse: func() error { return nil },
+				MRun: func(command tCommand[echoStruct], context iContext) error {
+					if _, ok := context.Context.Deadline(); !ok {
+						if _, err := context.Println("no deadline"); err != nil {
+							return fmt.Errorf("failed to write output: %w", err)
+						}
+						return nil
+					}
+					if len(command.Positionals.Arguments) > 0 {
+						if _, err := context.Println(command.Positionals.Arguments[0]); err != nil {
+							return fmt.Errorf("failed to write output: %w", err)
+						}
+						return nil
+					}
+					<-context.Context.Done()
+					return fmt.Errorf("interrupted: %w", context.Context.Err())
+				},
+			})
+
+			code, _ := exit.CodeFromError(program.Main(str, "", tt.args))
+
+			if gotCode := uint8(code); gotCode != tt.wantCode {
+				t.Errorf("Program.Main() code = %v, wantCode %v", gotCode, tt.wantCode)
+			}
+			if gotStdout := stdoutBuffer.String(); gotStdout != tt.wantStdout {
+				t.Errorf("Program.Main() stdout = %q, wantStdout %q", gotStdout, tt.wantStdout)
+			}
+			if gotStderr := stderrBuffer.String(); gotStderr != tt.wantStderr {
+				t.Errorf("Program.Main() stderr = %q, wantStderr %q", gotStderr, tt.wantStderr)
+			}
+		})
+	}
+}
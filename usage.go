@@ -1,59 +1,204 @@
 //spellchecker:words goprogram
 package goprogram
 
-//spellchecker:words essio shellescape github goprogram meta
+//spellchecker:words maps slices strings essio shellescape github goprogram exit meta
 import (
 	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
 
 	"al.essio.dev/pkg/shellescape"
+	"go.tkw01536.de/goprogram/exit"
 	"go.tkw01536.de/goprogram/meta"
 )
 
 //spellchecker:words positionals ggman
 
 // MainUsage returns a help page about ggman.
+//
+// When commands have categories, they are listed by category, see Categories.
 func (p Program[E, P, F, R]) MainUsage() meta.Meta {
-	commands := append(p.Commands(), p.Aliases()...)
+	commands := append(p.children(""), p.Aliases()...)
 
-	return meta.Meta{
+	usage := meta.Meta{
 		Executable:  p.Info.Executable,
-		GlobalFlags: globalOptions[F](),
+		GlobalFlags: p.globalOptions(),
 		Description: p.Info.Description,
 
-		Commands: commands,
+		Commands:  commands,
+		Summaries: p.summaries("", commands),
+	}
+	if categories := p.categories(); len(categories) > 0 {
+		usage.Categories = categories
+		usage.Aliases = p.aliasUsages("")
+	}
+	return usage
+}
+
+// otherCategory is the category of top-level commands and groups that do not have a category.
+const otherCategory = "Other"
+
+// categories sorts the top-level commands and groups into categories, see Categories.
+// When none of them has a category, returns nil.
+func (p Program[E, P, F, R]) categories() (categories []meta.Category) {
+	byName := make(map[string][]string)
+	for _, child := range p.children("") {
+		var category string
+		if group, ok := p.groups[child]; ok {
+			category = group.Category
+		} else {
+			category = p.commands[child].Description().Category
+		}
+		if category == "" {
+			category = otherCategory
+		}
+		byName[category] = append(byName[category], child)
+	}
+	if _, ok := byName[otherCategory]; ok && len(byName) == 1 {
+		return nil
+	}
+
+	// determine the order of categories
+	names := slices.Clone(p.Categories)
+	for _, name := range slices.Sorted(maps.Keys(byName)) {
+		if name != otherCategory && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
 	}
+	if !slices.Contains(names, otherCategory) {
+		names = append(names, otherCategory)
+	}
+
+	for _, name := range names {
+		if commands, ok := byName[name]; ok {
+			categories = append(categories, meta.Category{Name: name, Commands: commands})
+			delete(byName, name)
+		}
+	}
+	return categories
+}
+
+// GroupUsage generates the usage information about a specific group.
+func (p Program[E, P, F, R]) GroupUsage(group Group) meta.Meta {
+	children := p.children(group.Name)
+	return meta.Meta{
+		Executable:  p.Info.Executable,
+		GlobalFlags: p.globalOptions(),
+
+		Description: group.Description,
+
+		Command:   group.Name,
+		Group:     true,
+		Commands:  children,
+		Summaries: p.summaries(group.Name, children),
+	}
+}
+
+// summaries returns the summaries of the given children of group, see summary.
+// Children without a summary are omitted; when no child has a summary, returns nil.
+func (p Program[E, P, F, R]) summaries(group string, children []string) map[string]string {
+	var summaries map[string]string
+	for _, child := range children {
+		summary := p.summary(joinName(group, child))
+		if summary == "" {
+			continue
+		}
+		if summaries == nil {
+			summaries = make(map[string]string)
+		}
+		summaries[child] = summary
+	}
+	return summaries
+}
+
+// summary returns a one-line summary of the group, command or (top-level) alias with the given name.
+// This is the Summary of the group or command (if any), and the first sentence of its description otherwise.
+func (p Program[E, P, F, R]) summary(name string) string {
+	// names are looked up exactly, as they come from the program itself.
+	// this avoids abbreviations, and copying the command.
+	var summary, description string
+	if alias, ok := p.aliases[name]; ok {
+		description = alias.Description
+	} else if group, ok := p.groups[name]; ok {
+		summary, description = group.Summary, group.Description
+	} else if command, ok := p.commands[name]; ok {
+		summary, description = command.Description().Summary, command.Description().Description
+	}
+	if summary != "" {
+		return summary
+	}
+	return firstSentence(description)
+}
+
+// firstSentence returns the first sentence of the first line of text.
+func firstSentence(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+	if sentence, _, ok := strings.Cut(line, ". "); ok {
+		return sentence + "."
+	}
+	return line
 }
 
 // CommandUsage generates the usage information about a specific command.
+//
+// When the command is deprecated, the description includes the deprecation notice.
 func (p Program[E, P, F, R]) CommandUsage(context Context[E, P, F, R]) meta.Meta {
+	description := context.Description.Description
+	if deprecated := context.Description.Deprecated; deprecated != nil {
+		description = strings.TrimLeft(description+"\n\nWarning: "+deprecated.Notice(context.Description.Command), "\n")
+	}
+
 	return meta.Meta{
 		Executable:  p.Info.Executable,
-		GlobalFlags: globalFlagsFor[F](context.Description.Requirements),
+		GlobalFlags: p.globalFlagsFor(context.Description.Requirements),
 
-		Description: context.Description.Description,
+		Description: description,
 
 		Command:      context.Description.Command,
 		CommandFlags: context.parser.Flags(),
 
 		Positionals: context.parser.Positionals(),
+
+		Examples: context.Description.Examples,
 	}
 }
 
 // AliasPage returns a usage page for the provided alias.
+//
+// When the alias refers to other aliases, the page describes the full chain of expansions.
 func (p Program[E, P, F, R]) AliasUsage(context Context[E, P, F, R], alias Alias) meta.Meta {
-	exCmd := "`" + shellescape.QuoteCommand(append([]string{p.Info.Executable}, alias.Expansion()...)) + "`"
-	helpCmd := "`" + shellescape.QuoteCommand([]string{p.Info.Executable, alias.Command, "--help"}) + "`"
-	name := shellescape.Quote(alias.Command)
+	chain, err := p.aliasChain(alias)
+	if err != nil {
+		chain = []Alias{alias}
+	}
+
+	// expand each alias in the chain
+	var expansion Alias
+	expansions := make([]string, len(chain))
+	for i, a := range chain {
+		expansion.Command, expansion.Args = a.Invoke(expansion.Args)
+		expansions[i] = "`" + shellescape.QuoteCommand(append([]string{p.Info.Executable}, expansion.Expansion()...)) + "`"
+	}
+
+	exCmd := expansions[len(expansions)-1]
+	helpCmd := "`" + shellescape.QuoteCommand(append(append([]string{p.Info.Executable}, strings.Fields(expansion.Command)...), "--help")) + "`"
+	name := shellescape.Quote(expansion.Command)
 
 	var description string
 	if alias.Description != "" {
 		description = alias.Description + "\n\n"
 	}
-	description += fmt.Sprintf("alias for %s. see %s for detailed help page about %s", exCmd, helpCmd, name)
+	description += "alias for " + expansions[0]
+	for _, ex := range expansions[1:] {
+		description += ", expanding to " + ex
+	}
+	description += fmt.Sprintf(". see %s for detailed help page about %s", helpCmd, name)
 
 	return meta.Meta{
 		Executable:  p.Info.Executable,
-		GlobalFlags: globalFlagsFor[F](context.Description.Requirements),
+		GlobalFlags: p.globalFlagsFor(context.Description.Requirements),
 
 		Description: description,
 
@@ -70,3 +215,79 @@ func (p Program[E, P, F, R]) AliasUsage(context Context[E, P, F, R], alias Alias
 		},
 	}
 }
+
+// usage returns usage information about the program (when name is empty), or the group or command with the given name.
+// Unlike MainUsage, the returned meta.Meta lists aliases separately from commands.
+//
+// If there is no group or command with the given name, returns an error of type exit.Error.
+func (p Program[E, P, F, R]) usage(name string) (meta.Meta, error) {
+	var usage meta.Meta
+	if name == "" {
+		usage = p.MainUsage()
+		usage.Commands = p.children("")
+	} else if group, ok := p.Group(name); ok {
+		usage = p.GroupUsage(group)
+	} else if command, ok := p.Command(name); ok {
+		context := Context[E, P, F, R]{Program: p}
+		context.Description = command.Description()
+		context.parser = p.commandParser(command)
+		usage = p.CommandUsage(context)
+	} else {
+		return meta.Meta{}, fmt.Errorf("%w %q: must be one of %s", errProgramUnknownCommand, name, meta.JoinCommands(append(p.Groups(), p.listedCommands()...)))
+	}
+
+	usage.Aliases = p.aliasUsages(name)
+	return usage, nil
+}
+
+// aliasUsages returns meta-information about aliases that expand to the command or group with the given name.
+// An alias expanding to a group followed by arguments naming a child refers to that child, as when invoking the alias.
+// When name is empty, returns information about all aliases.
+func (p Program[E, P, F, R]) aliasUsages(name string) (aliases []meta.Alias) {
+	for _, alias := range p.Aliases() {
+		chain, err := p.expandAliases(alias)
+		if err != nil {
+			continue
+		}
+
+		var expansion Alias
+		for _, a := range chain {
+			expansion.Command, expansion.Args = a.Invoke(expansion.Args)
+		}
+		if name != "" {
+			target, _, err := p.descendGroups(expansion.Command, expansion.Args)
+			if err != nil || target != name {
+				continue
+			}
+		}
+
+		aliases = append(aliases, meta.Alias{
+			Name:        alias,
+			Description: chain[0].Description,
+			Expansion:   expansion.Expansion(),
+		})
+	}
+	return aliases
+}
+
+var errUsageWrite = exit.NewErrorWithCode("unable to write usage", exit.ExitGeneric)
+
+// WriteUsageJSON writes machine-readable usage information about the program (when name is empty), or the group or command with the given name to w.
+// The information includes flags, positionals, sub-commands and the expansions of aliases referring to the object.
+// See meta.Meta.WriteJSONTo for the format.
+//
+// It is intended to be used by integrations with other tools.
+// The information is only available via this method; there is deliberately no command line flag to print it.
+// Programs wishing to expose it can call this method from a command of their own.
+// The returned error is nil, or of type exit.Error.
+func (p Program[E, P, F, R]) WriteUsageJSON(w io.Writer, name string) error {
+	usage, err := p.usage(name)
+	if err != nil {
+		return err
+	}
+
+	if err := usage.WriteJSONTo(w); err != nil {
+		return fmt.Errorf("%w: %w", errUsageWrite, err)
+	}
+	return nil
+}
@@ -1,9 +1,15 @@
 //spellchecker:words goprogram
 package goprogram //nolint:testpackage
 
-//spellchecker:words reflect testing github goprogram meta parser
+//spellchecker:words bytes encoding json errors reflect strings testing github goprogram meta parser
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"reflect"
+	"strings"
 	"testing"
 
 	"go.tkw01536.de/goprogram/meta"
@@ -22,12 +28,125 @@ func TestProgram_MainUsage(t *testing.T) {
 	program.Register(makeEchoCommand("b"))
 
 	got := program.MainUsage()
-	want := meta.Meta{Executable: "exe", Command: "", Description: "something something dark side", GlobalFlags: []meta.Flag{{FieldName: "Help", Short: []string{"h"}, Long: []string{"help"}, Required: false, Value: "", Usage: "print a help message and exit", Default: ""}, {FieldName: "Version", Short: []string{"v"}, Long: []string{"version"}, Required: false, Value: "", Usage: "print a version message and exit", Default: ""}, {FieldName: "GlobalOne", Short: []string{"a"}, Long: []string{"global-one"}, Required: false, Value: "", Usage: "", Default: ""}, {FieldName: "GlobalTwo", Short: []string{"b"}, Long: []string{"global-two"}, Required: false, Value: "", Usage: "", Default: ""}}, CommandFlags: []meta.Flag(nil), Positionals: []meta.Positional(nil), Commands: []string{"a", "b", "c"}}
+	want := meta.Meta{Executable: "exe", Command: "", Description: "something something dark side", GlobalFlags: []meta.Flag{{FieldName: "Help", Short: []string{"h"}, Long: []string{"help"}, Required: false, Boolean: true, Value: "", Usage: "print a help message and exit", Default: ""}, {FieldName: "Version", Short: []string{"v"}, Long: []string{"version"}, Required: false, Boolean: true, Value: "", Usage: "print a version message and exit", Default: ""}, {FieldName: "Timeout", Short: []string(nil), Long: []string{"timeout"}, Required: false, Value: "duration", Usage: "maximum time the command may run", Default: ""}, {FieldName: "Color", Long: []string{"color"}, Value: "when", Usage: "when to use colors in output", Choices: []string{"auto", "always", "never"}, Default: "auto"}, {FieldName: "GlobalOne", Short: []string{"a"}, Long: []string{"global-one"}, Required: false, Value: "", Usage: "", Default: ""}, {FieldName: "GlobalTwo", Short: []string{"b"}, Long: []string{"global-two"}, Required: false, Value: "", Usage: "", Default: ""}}, CommandFlags: []meta.Flag(nil), Positionals: []meta.Positional(nil), Commands: []string{"a", "b", "c"}}
 	if !reflect.DeepEqual(got, want) {
 		t.Errorf("Program.MainUsage() = %#v, want %#v", got, want)
 	}
 }
 
+func TestProgram_MainUsage_categories(t *testing.T) {
+	t.Parallel()
+
+	withCategory := func(name, category string) iCommand {
+		command := makeEchoCommand(name).(*tCommand[echoStruct])
+		command.MDesc.Category = category
+		return command
+	}
+
+	program := makeProgram()
+	program.Categories = []string{"Repository", "Unused"}
+	program.RegisterGroup(Group{Name: "remote", Category: "Repository"})
+	program.Register(withCategory("remote add", "Ignored"))
+	program.Register(withCategory("gc", "Maintenance"))
+	program.Register(withCategory("fsck", "Maintenance"))
+	program.Register(withCategory("clone", "Repository"))
+	program.Register(withCategory("echo", ""))
+	program.Register(withCategory("zap", "Danger"))
+	program.RegisterAlias(Alias{Name: "c", Command: "clone", Args: []string{"--depth", "1"}})
+
+	got := program.MainUsage()
+
+	wantCategories := []meta.Category{
+		{Name: "Repository", Commands: []string{"clone", "remote"}},
+		{Name: "Danger", Commands: []string{"zap"}},
+		{Name: "Maintenance", Commands: []string{"fsck", "gc"}},
+		{Name: "Other", Commands: []string{"echo"}},
+	}
+	if !reflect.DeepEqual(got.Categories, wantCategories) {
+		t.Errorf("Program.MainUsage().Categories = %#v, want %#v", got.Categories, wantCategories)
+	}
+
+	wantAliases := []meta.Alias{{Name: "c", Expansion: []string{"clone", "--depth", "1"}}}
+	if !reflect.DeepEqual(got.Aliases, wantAliases) {
+		t.Errorf("Program.MainUsage().Aliases = %#v, want %#v", got.Aliases, wantAliases)
+	}
+}
+
+func TestProgram_MainUsage_summaries(t *testing.T) {
+	t.Parallel()
+
+	withDescription := func(name, summary, description string) iCommand {
+		command := makeEchoCommand(name).(*tCommand[echoStruct])
+		command.MDesc.Summary = summary
+		command.MDesc.Description = description
+		return command
+	}
+
+	program := makeProgram()
+	program.RegisterGroup(Group{Name: "remote", Description: "Manage remotes.\nRemotes are other copies."})
+	program.Register(withDescription("remote add", "", "Add a remote. The remote must exist."))
+	program.Register(withDescription("clone", "clone a repository", "Clone a repository. Supports many protocols."))
+	program.Register(withDescription("echo", "", ""))
+	program.RegisterAlias(Alias{Name: "c", Command: "clone", Description: "Clone shallowly"})
+
+	got := program.MainUsage().Summaries
+	want := map[string]string{
+		"remote": "Manage remotes.",
+		"clone":  "clone a repository",
+		"c":      "Clone shallowly",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Program.MainUsage().Summaries = %v, want %v", got, want)
+	}
+
+	group, _ := program.Group("remote")
+	gotGroup := program.GroupUsage(group).Summaries
+	wantGroup := map[string]string{"add": "Add a remote."}
+	if !reflect.DeepEqual(gotGroup, wantGroup) {
+		t.Errorf("Program.GroupUsage().Summaries = %v, want %v", gotGroup, wantGroup)
+	}
+}
+
+func TestProgram_MainUsage_summariesAbbreviated(t *testing.T) {
+	t.Parallel()
+
+	command := makeEchoCommand("status").(*tCommand[echoStruct])
+	command.MDesc.Summary = "show the status"
+
+	program := makeProgram()
+	program.AbbreviateCommands = true
+	program.Register(command)
+	program.RegisterAlias(Alias{Name: "st", Command: "status", Args: []string{"--short"}})
+
+	// the alias has no description, and must not be mistaken for an abbreviation of "status"
+	got := program.MainUsage().Summaries
+	want := map[string]string{"status": "show the status"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Program.MainUsage().Summaries = %v, want %v", got, want)
+	}
+}
+
+func Test_firstSentence(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"", ""},
+		{"no full stop", "no full stop"},
+		{"One sentence.", "One sentence."},
+		{"First sentence. Second sentence.", "First sentence."},
+		{"First line\nSecond line. More.", "First line"},
+		{"Version 1.2 is great", "Version 1.2 is great"},
+	}
+	for _, tt := range tests {
+		if got := firstSentence(tt.text); got != tt.want {
+			t.Errorf("firstSentence(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
 // makeTPM_Positionals makes a new parser with the provided positional arguments.
 func makeTPCU_Positionals[Pos any]() parser.Parser {
 	return parser.NewCommandParser(&struct {
@@ -64,7 +183,7 @@ func TestProgram_CommandUsage(t *testing.T) {
 		{
 			"command without args and allowing all globals",
 			args{Command: "cmd", Requirement: reqAny, Positionals: makeTPCU_Positionals[struct{}]()},
-			meta.Meta{Executable: "exe", Command: "cmd", Description: "", GlobalFlags: []meta.Flag{{FieldName: "Help", Short: []string{"h"}, Long: []string{"help"}, Required: false, Value: "", Usage: "print a help message and exit", Default: ""}, {FieldName: "Version", Short: []string{"v"}, Long: []string{"version"}, Required: false, Value: "", Usage: "print a version message and exit", Default: ""}, {FieldName: "GlobalOne", Short: []string{"a"}, Long: []string{"global-one"}, Required: false, Value: "", Usage: "", Default: ""}, {FieldName: "GlobalTwo", Short: []string{"b"}, Long: []string{"global-two"}, Required: false, Value: "", Usage: "", Default: ""}}, CommandFlags: []meta.Flag{{FieldName: "Boolean", Short: []string{"b"}, Long: []string{"bool"}, Required: false, Value: "random", Usage: "a random boolean argument with short", Default: ""}, {FieldName: "Int", Short: []string(nil), Long: []string{"int"}, Required: false, Value: "dummy", Usage: "a dummy integer flag", Default: "12"}}, Positionals: []meta.Positional{}, Commands: []string(nil)},
+			meta.Meta{Executable: "exe", Command: "cmd", Description: "", GlobalFlags: []meta.Flag{{FieldName: "Help", Short: []string{"h"}, Long: []string{"help"}, Required: false, Boolean: true, Value: "", Usage: "print a help message and exit", Default: ""}, {FieldName: "Version", Short: []string{"v"}, Long: []string{"version"}, Required: false, Boolean: true, Value: "", Usage: "print a version message and exit", Default: ""}, {FieldName: "Timeout", Short: []string(nil), Long: []string{"timeout"}, Required: false, Value: "duration", Usage: "maximum time the command may run", Default: ""}, {FieldName: "Color", Long: []string{"color"}, Value: "when", Usage: "when to use colors in output", Choices: []string{"auto", "always", "never"}, Default: "auto"}, {FieldName: "GlobalOne", Short: []string{"a"}, Long: []string{"global-one"}, Required: false, Value: "", Usage: "", Default: ""}, {FieldName: "GlobalTwo", Short: []string{"b"}, Long: []string{"global-two"}, Required: false, Value: "", Usage: "", Default: ""}}, CommandFlags: []meta.Flag{{FieldName: "Boolean", Short: []string{"b"}, Long: []string{"bool"}, Required: false, Boolean: true, Value: "random", Usage: "a random boolean argument with short", Default: ""}, {FieldName: "Int", Short: []string(nil), Long: []string{"int"}, Required: false, Value: "dummy", Usage: "a dummy integer flag", Default: "12"}}, Positionals: []meta.Positional{}, Commands: []string(nil)},
 		},
 
 		{
@@ -72,7 +191,7 @@ func TestProgram_CommandUsage(t *testing.T) {
 			args{Command: "cmd", Requirement: reqOne, Positionals: makeTPCU_Positionals[struct {
 				Meta string `description:"usage" positional-arg-name:"META"`
 			}]()},
-			meta.Meta{Executable: "exe", Command: "cmd", Description: "", GlobalFlags: []meta.Flag{{FieldName: "Help", Short: []string{"h"}, Long: []string{"help"}, Required: false, Value: "", Usage: "print a help message and exit", Default: ""}, {FieldName: "Version", Short: []string{"v"}, Long: []string{"version"}, Required: false, Value: "", Usage: "print a version message and exit", Default: ""}}, CommandFlags: []meta.Flag{{FieldName: "Boolean", Short: []string{"b"}, Long: []string{"bool"}, Required: false, Value: "random", Usage: "a random boolean argument with short", Default: ""}, {FieldName: "Int", Short: []string(nil), Long: []string{"int"}, Required: false, Value: "dummy", Usage: "a dummy integer flag", Default: "12"}}, Positionals: []meta.Positional{{Value: "META", Usage: "usage", Min: 0, Max: 1}}, Commands: []string(nil)},
+			meta.Meta{Executable: "exe", Command: "cmd", Description: "", GlobalFlags: []meta.Flag{{FieldName: "Help", Short: []string{"h"}, Long: []string{"help"}, Required: false, Boolean: true, Value: "", Usage: "print a help message and exit", Default: ""}, {FieldName: "Version", Short: []string{"v"}, Long: []string{"version"}, Required: false, Boolean: true, Value: "", Usage: "print a version message and exit", Default: ""}, {FieldName: "Timeout", Short: []string(nil), Long: []string{"timeout"}, Required: false, Value: "duration", Usage: "maximum time the command may run", Default: ""}, {FieldName: "Color", Long: []string{"color"}, Value: "when", Usage: "when to use colors in output", Choices: []string{"auto", "always", "never"}, Default: "auto"}}, CommandFlags: []meta.Flag{{FieldName: "Boolean", Short: []string{"b"}, Long: []string{"bool"}, Required: false, Boolean: true, Value: "random", Usage: "a random boolean argument with short", Default: ""}, {FieldName: "Int", Short: []string(nil), Long: []string{"int"}, Required: false, Value: "dummy", Usage: "a dummy integer flag", Default: "12"}}, Positionals: []meta.Positional{{Value: "META", Usage: "usage", Min: 0, Max: 1}}, Commands: []string(nil)},
 		},
 
 		{
@@ -80,7 +199,7 @@ func TestProgram_CommandUsage(t *testing.T) {
 			args{Command: "cmd", Requirement: reqOne, Positionals: makeTPCU_Positionals[struct {
 				Meta []string `description:"usage" positional-arg-name:"META" required:"0-4"`
 			}]()},
-			meta.Meta{Executable: "exe", Command: "cmd", Description: "", GlobalFlags: []meta.Flag{{FieldName: "Help", Short: []string{"h"}, Long: []string{"help"}, Required: false, Value: "", Usage: "print a help message and exit", Default: ""}, {FieldName: "Version", Short: []string{"v"}, Long: []string{"version"}, Required: false, Value: "", Usage: "print a version message and exit", Default: ""}}, CommandFlags: []meta.Flag{{FieldName: "Boolean", Short: []string{"b"}, Long: []string{"bool"}, Required: false, Value: "random", Usage: "a random boolean argument with short", Default: ""}, {FieldName: "Int", Short: []string(nil), Long: []string{"int"}, Required: false, Value: "dummy", Usage: "a dummy integer flag", Default: "12"}}, Positionals: []meta.Positional{{Value: "META", Usage: "usage", Min: 0, Max: 4}}, Commands: []string(nil)},
+			meta.Meta{Executable: "exe", Command: "cmd", Description: "", GlobalFlags: []meta.Flag{{FieldName: "Help", Short: []string{"h"}, Long: []string{"help"}, Required: false, Boolean: true, Value: "", Usage: "print a help message and exit", Default: ""}, {FieldName: "Version", Short: []string{"v"}, Long: []string{"version"}, Required: false, Boolean: true, Value: "", Usage: "print a version message and exit", Default: ""}, {FieldName: "Timeout", Short: []string(nil), Long: []string{"timeout"}, Required: false, Value: "duration", Usage: "maximum time the command may run", Default: ""}, {FieldName: "Color", Long: []string{"color"}, Value: "when", Usage: "when to use colors in output", Choices: []string{"auto", "always", "never"}, Default: "auto"}}, CommandFlags: []meta.Flag{{FieldName: "Boolean", Short: []string{"b"}, Long: []string{"bool"}, Required: false, Boolean: true, Value: "random", Usage: "a random boolean argument with short", Default: ""}, {FieldName: "Int", Short: []string(nil), Long: []string{"int"}, Required: false, Value: "dummy", Usage: "a dummy integer flag", Default: "12"}}, Positionals: []meta.Positional{{Value: "META", Usage: "usage", Min: 0, Max: 4}}, Commands: []string(nil)},
 		},
 
 		{
@@ -88,7 +207,7 @@ func TestProgram_CommandUsage(t *testing.T) {
 			args{Command: "cmd", Requirement: reqOne, Positionals: makeTPCU_Positionals[struct {
 				Meta []string `description:"usage" positional-arg-name:"META" required:"1-2"`
 			}]()},
-			meta.Meta{Executable: "exe", Command: "cmd", Description: "", GlobalFlags: []meta.Flag{{FieldName: "Help", Short: []string{"h"}, Long: []string{"help"}, Required: false, Value: "", Usage: "print a help message and exit", Default: ""}, {FieldName: "Version", Short: []string{"v"}, Long: []string{"version"}, Required: false, Value: "", Usage: "print a version message and exit", Default: ""}}, CommandFlags: []meta.Flag{{FieldName: "Boolean", Short: []string{"b"}, Long: []string{"bool"}, Required: false, Value: "random", Usage: "a random boolean argument with short", Default: ""}, {FieldName: "Int", Short: []string(nil), Long: []string{"int"}, Required: false, Value: "dummy", Usage: "a dummy integer flag", Default: "12"}}, Positionals: []meta.Positional{{Value: "META", Usage: "usage", Min: 1, Max: 2}}, Commands: []string(nil)},
+			meta.Meta{Executable: "exe", Command: "cmd", Description: "", GlobalFlags: []meta.Flag{{FieldName: "Help", Short: []string{"h"}, Long: []string{"help"}, Required: false, Boolean: true, Value: "", Usage: "print a help message and exit", Default: ""}, {FieldName: "Version", Short: []string{"v"}, Long: []string{"version"}, Required: false, Boolean: true, Value: "", Usage: "print a version message and exit", Default: ""}, {FieldName: "Timeout", Short: []string(nil), Long: []string{"timeout"}, Required: false, Value: "duration", Usage: "maximum time the command may run", Default: ""}, {FieldName: "Color", Long: []string{"color"}, Value: "when", Usage: "when to use colors in output", Choices: []string{"auto", "always", "never"}, Default: "auto"}}, CommandFlags: []meta.Flag{{FieldName: "Boolean", Short: []string{"b"}, Long: []string{"bool"}, Required: false, Boolean: true, Value: "random", Usage: "a random boolean argument with short", Default: ""}, {FieldName: "Int", Short: []string(nil), Long: []string{"int"}, Required: false, Value: "dummy", Usage: "a dummy integer flag", Default: "12"}}, Positionals: []meta.Positional{{Value: "META", Usage: "usage", Min: 1, Max: 2}}, Commands: []string(nil)},
 		},
 
 		{
@@ -96,7 +215,7 @@ func TestProgram_CommandUsage(t *testing.T) {
 			args{Command: "cmd", Requirement: reqOne, Positionals: makeTPCU_Positionals[struct {
 				Meta []string `description:"usage" positional-arg-name:"META" required:"1"`
 			}]()},
-			meta.Meta{Executable: "exe", Command: "cmd", Description: "", GlobalFlags: []meta.Flag{{FieldName: "Help", Short: []string{"h"}, Long: []string{"help"}, Required: false, Value: "", Usage: "print a help message and exit", Default: ""}, {FieldName: "Version", Short: []string{"v"}, Long: []string{"version"}, Required: false, Value: "", Usage: "print a version message and exit", Default: ""}}, CommandFlags: []meta.Flag{{FieldName: "Boolean", Short: []string{"b"}, Long: []string{"bool"}, Required: false, Value: "random", Usage: "a random boolean argument with short", Default: ""}, {FieldName: "Int", Short: []string(nil), Long: []string{"int"}, Required: false, Value: "dummy", Usage: "a dummy integer flag", Default: "12"}}, Positionals: []meta.Positional{{Value: "META", Usage: "usage", Min: 1, Max: -1}}, Commands: []string(nil)},
+			meta.Meta{Executable: "exe", Command: "cmd", Description: "", GlobalFlags: []meta.Flag{{FieldName: "Help", Short: []string{"h"}, Long: []string{"help"}, Required: false, Boolean: true, Value: "", Usage: "print a help message and exit", Default: ""}, {FieldName: "Version", Short: []string{"v"}, Long: []string{"version"}, Required: false, Boolean: true, Value: "", Usage: "print a version message and exit", Default: ""}, {FieldName: "Timeout", Short: []string(nil), Long: []string{"timeout"}, Required: false, Value: "duration", Usage: "maximum time the command may run", Default: ""}, {FieldName: "Color", Long: []string{"color"}, Value: "when", Usage: "when to use colors in output", Choices: []string{"auto", "always", "never"}, Default: "auto"}}, CommandFlags: []meta.Flag{{FieldName: "Boolean", Short: []string{"b"}, Long: []string{"bool"}, Required: false, Boolean: true, Value: "random", Usage: "a random boolean argument with short", Default: ""}, {FieldName: "Int", Short: []string(nil), Long: []string{"int"}, Required: false, Value: "dummy", Usage: "a dummy integer flag", Default: "12"}}, Positionals: []meta.Positional{{Value: "META", Usage: "usage", Min: 1, Max: -1}}, Commands: []string(nil)},
 		},
 
 		{
@@ -104,7 +223,7 @@ func TestProgram_CommandUsage(t *testing.T) {
 			args{Command: "cmd", Description: "A fake command", Requirement: reqOne, Positionals: makeTPCU_Positionals[struct {
 				Meta []string `description:"usage" positional-arg-name:"META" required:"1"`
 			}]()},
-			meta.Meta{Executable: "exe", Command: "cmd", Description: "A fake command", GlobalFlags: []meta.Flag{{FieldName: "Help", Short: []string{"h"}, Long: []string{"help"}, Required: false, Value: "", Usage: "print a help message and exit", Default: ""}, {FieldName: "Version", Short: []string{"v"}, Long: []string{"version"}, Required: false, Value: "", Usage: "print a version message and exit", Default: ""}}, CommandFlags: []meta.Flag{{FieldName: "Boolean", Short: []string{"b"}, Long: []string{"bool"}, Required: false, Value: "random", Usage: "a random boolean argument with short", Default: ""}, {FieldName: "Int", Short: []string(nil), Long: []string{"int"}, Required: false, Value: "dummy", Usage: "a dummy integer flag", Default: "12"}}, Positionals: []meta.Positional{{Value: "META", Usage: "usage", Min: 1, Max: -1}}, Commands: []string(nil)},
+			meta.Meta{Executable: "exe", Command: "cmd", Description: "A fake command", GlobalFlags: []meta.Flag{{FieldName: "Help", Short: []string{"h"}, Long: []string{"help"}, Required: false, Boolean: true, Value: "", Usage: "print a help message and exit", Default: ""}, {FieldName: "Version", Short: []string{"v"}, Long: []string{"version"}, Required: false, Boolean: true, Value: "", Usage: "print a version message and exit", Default: ""}, {FieldName: "Timeout", Short: []string(nil), Long: []string{"timeout"}, Required: false, Value: "duration", Usage: "maximum time the command may run", Default: ""}, {FieldName: "Color", Long: []string{"color"}, Value: "when", Usage: "when to use colors in output", Choices: []string{"auto", "always", "never"}, Default: "auto"}}, CommandFlags: []meta.Flag{{FieldName: "Boolean", Short: []string{"b"}, Long: []string{"bool"}, Required: false, Boolean: true, Value: "random", Usage: "a random boolean argument with short", Default: ""}, {FieldName: "Int", Short: []string(nil), Long: []string{"int"}, Required: false, Value: "dummy", Usage: "a dummy integer flag", Default: "12"}}, Positionals: []meta.Positional{{Value: "META", Usage: "usage", Min: 1, Max: -1}}, Commands: []string(nil)},
 		},
 	}
 	for _, tt := range tests {
@@ -150,8 +269,110 @@ func TestProgram_AliasUsage(t *testing.T) {
 	}
 
 	got := program.AliasUsage(context, alias)
-	want := meta.Meta{Executable: "exe", Command: "nice", Description: "Do one nice thing\n\nalias for `exe a nice command`. see `exe a --help` for detailed help page about a", GlobalFlags: []meta.Flag{{FieldName: "Help", Short: []string{"h"}, Long: []string{"help"}, Required: false, Value: "", Usage: "print a help message and exit", Default: ""}, {FieldName: "Version", Short: []string{"v"}, Long: []string{"version"}, Required: false, Value: "", Usage: "print a version message and exit", Default: ""}, {FieldName: "GlobalOne", Short: []string{"a"}, Long: []string{"global-one"}, Required: false, Value: "", Usage: "", Default: ""}, {FieldName: "GlobalTwo", Short: []string{"b"}, Long: []string{"global-two"}, Required: false, Value: "", Usage: "", Default: ""}}, CommandFlags: []meta.Flag(nil), Positionals: []meta.Positional{{Value: "ARG", Usage: "arguments to pass after `exe a nice command`", Min: 0, Max: -1}}, Commands: []string(nil)}
+	want := meta.Meta{Executable: "exe", Command: "nice", Description: "Do one nice thing\n\nalias for `exe a nice command`. see `exe a --help` for detailed help page about a", GlobalFlags: []meta.Flag{{FieldName: "Help", Short: []string{"h"}, Long: []string{"help"}, Required: false, Boolean: true, Value: "", Usage: "print a help message and exit", Default: ""}, {FieldName: "Version", Short: []string{"v"}, Long: []string{"version"}, Required: false, Boolean: true, Value: "", Usage: "print a version message and exit", Default: ""}, {FieldName: "Timeout", Short: []string(nil), Long: []string{"timeout"}, Required: false, Value: "duration", Usage: "maximum time the command may run", Default: ""}, {FieldName: "Color", Long: []string{"color"}, Value: "when", Usage: "when to use colors in output", Choices: []string{"auto", "always", "never"}, Default: "auto"}, {FieldName: "GlobalOne", Short: []string{"a"}, Long: []string{"global-one"}, Required: false, Value: "", Usage: "", Default: ""}, {FieldName: "GlobalTwo", Short: []string{"b"}, Long: []string{"global-two"}, Required: false, Value: "", Usage: "", Default: ""}}, CommandFlags: []meta.Flag(nil), Positionals: []meta.Positional{{Value: "ARG", Usage: "arguments to pass after `exe a nice command`", Min: 0, Max: -1}}, Commands: []string(nil)}
 	if !reflect.DeepEqual(got, want) {
 		t.Errorf("Program.AliasUsage() = %#v, want %#v", got, want)
 	}
 }
+
+// Write machine-readable usage information for a command.
+// There is no command line flag for this, programs need to call WriteUsageJSON themselves.
+func ExampleProgram_WriteUsageJSON() {
+	// create a program with a nested command and an alias to it
+	p := makeProgram()
+	p.RegisterGroup(Group{Name: "repo", Description: "manage repositories"})
+	p.Register(makeEchoCommand("repo list"))
+	p.RegisterAlias(Alias{Name: "ls", Command: "repo", Args: []string{"list"}})
+
+	var buffer bytes.Buffer
+	if err := p.WriteUsageJSON(&buffer, "repo list"); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// decode it again, as an integration would
+	var usage meta.Meta
+	if err := json.Unmarshal(buffer.Bytes(), &usage); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(usage.Executable, usage.Command, usage.Aliases[0].Name, usage.Aliases[0].Expansion)
+
+	// Output: exe repo list ls [repo list]
+}
+
+func TestProgram_WriteUsageJSON(t *testing.T) {
+	t.Parallel()
+
+	p := makeCompletionProgram()
+
+	tests := []struct {
+		name string
+		want meta.Meta
+	}{
+		{
+			name: "",
+			want: meta.Meta{
+				Executable:  "exe",
+				Description: "something something dark side",
+				Commands:    []string{"paint", "repo"},
+				Aliases:     []meta.Alias{{Name: "p", Expansion: []string{"paint"}}},
+			},
+		},
+		{
+			name: "repo",
+			want: meta.Meta{
+				Executable: "exe",
+				Command:    "repo",
+				Group:      true,
+				Commands:   []string{"list", "show"},
+			},
+		},
+		{
+			name: "paint",
+			want: meta.Meta{
+				Executable: "exe",
+				Command:    "paint",
+				CommandFlags: []meta.Flag{
+					{Short: []string{"c"}, Long: []string{"color"}, Choices: []string{"red", "blue"}},
+					{Long: []string{"output"}, Value: "file"},
+					{Short: []string{"v"}, Long: []string{"verbose"}, Boolean: true},
+				},
+				Aliases: []meta.Alias{{Name: "p", Expansion: []string{"paint"}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var builder strings.Builder
+			if err := p.WriteUsageJSON(&builder, tt.name); err != nil {
+				t.Fatalf("Program.WriteUsageJSON() error = %v", err)
+			}
+
+			var got meta.Meta
+			if err := json.Unmarshal([]byte(builder.String()), &got); err != nil {
+				t.Fatalf("Program.WriteUsageJSON() returned invalid json: %v", err)
+			}
+
+			// global flags are tested elsewhere
+			got.GlobalFlags = nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Program.WriteUsageJSON() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgram_WriteUsageJSON_unknown(t *testing.T) {
+	t.Parallel()
+
+	p := makeCompletionProgram()
+
+	err := p.WriteUsageJSON(io.Discard, "nope")
+	if !errors.Is(err, errProgramUnknownCommand) {
+		t.Errorf("Program.WriteUsageJSON() error = %v, want errProgramUnknownCommand", err)
+	}
+}
@@ -0,0 +1,148 @@
+//spellchecker:words goprogram
+package goprogram
+
+//spellchecker:words errors slices github goprogram meta parser
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"go.tkw01536.de/goprogram/meta"
+	"go.tkw01536.de/goprogram/parser"
+)
+
+// FlagReferencer may be implemented by a Requirement that refers to specific global flags.
+// It is used by Program.Validate to check that the referenced flags exist.
+type FlagReferencer interface {
+	// ReferencedFlags returns the names of the fields of the flags type F the requirement refers to.
+	ReferencedFlags() []string
+}
+
+var (
+	errValidateClash       = errors.New("name clash")
+	errValidateAlias       = errors.New("invalid alias")
+	errValidateShadow      = errors.New("flag shadows global flag")
+	errValidatePositional  = errors.New("invalid range of positional arguments")
+	errValidateRequirement = errors.New("requirement refers to unknown global flag")
+)
+
+// Validate checks that the keywords, aliases, groups and commands registered with this program are consistent.
+// It is intended to be called from a unit test, once everything has been registered.
+//
+// In particular, Validate checks that:
+//   - no alias, group or command is hidden by a keyword of the same name;
+//   - no group or command is hidden by an alias of the same name, unless the alias refers to its own name;
+//   - every alias eventually expands into an existing group or command, including any children of groups named by its arguments;
+//   - no command flag has the same long or short name as a universal or global flag;
+//   - the positional arguments of every command have a valid range, see meta.Positional.ValidRange;
+//   - requirements implementing FlagReferencer only refer to fields of the flags type F.
+//
+// Validate reports every problem found, combined using errors.Join.
+// When no problems are found, it returns nil.
+func (p Program[E, P, F, R]) Validate() error {
+	var errs []error
+
+	// keywords take precedence over everything else
+	for _, name := range p.Keywords() {
+		for _, kind := range p.kindsOf(name) {
+			errs = append(errs, fmt.Errorf("%w: keyword %q hides %s of the same name", errValidateClash, name, kind))
+		}
+	}
+
+	// aliases take precedence over groups and commands
+	for _, name := range p.Aliases() {
+		alias := p.aliases[name]
+		if alias.Command != alias.Name {
+			if p.hasGroup(name) {
+				errs = append(errs, fmt.Errorf("%w: alias %q hides group of the same name", errValidateClash, name))
+			}
+			if _, ok := p.commands[name]; ok {
+				errs = append(errs, fmt.Errorf("%w: alias %q hides command of the same name", errValidateClash, name))
+			}
+		}
+
+		chain, err := p.aliasChain(alias)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w %q: %w", errValidateAlias, name, err))
+			continue
+		}
+		var expansion Alias
+		for _, a := range chain {
+			expansion.Command, expansion.Args = a.Invoke(expansion.Args)
+		}
+
+		// arguments following a group must name one of its children
+		target, _, err := p.descendGroups(expansion.Command, expansion.Args)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("%w %q: %w", errValidateAlias, name, err))
+		case p.commands[target] == nil && !p.hasGroup(target):
+			errs = append(errs, fmt.Errorf("%w %q: expands into unknown command %q", errValidateAlias, name, target))
+		}
+	}
+
+	// check each of the commands
+	globals := p.globalOptions()
+	fields := make([]string, 0, len(globals))
+	for _, flag := range parser.AllFlags[F]() {
+		fields = append(fields, flag.FieldName)
+	}
+	for _, name := range p.Commands() {
+		command := p.commands[name]
+		cp := p.commandParser(command)
+
+		for _, flag := range cp.Flags() {
+			if clash, ok := flagClash(flag, globals); ok {
+				errs = append(errs, fmt.Errorf("%w: command %q: %q", errValidateShadow, name, clash))
+			}
+		}
+
+		for _, pos := range cp.Positionals() {
+			if !pos.ValidRange() {
+				errs = append(errs, fmt.Errorf("%w: command %q: %q takes between %d and %d arguments", errValidatePositional, name, pos.Value, pos.Min, pos.Max))
+			}
+		}
+
+		if referencer, ok := any(command.Description().Requirements).(FlagReferencer); ok {
+			for _, field := range referencer.ReferencedFlags() {
+				if !slices.Contains(fields, field) {
+					errs = append(errs, fmt.Errorf("%w: command %q: %q", errValidateRequirement, name, field))
+				}
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// kindsOf returns the kinds of items (other than keywords) registered with the given name.
+func (p Program[E, P, F, R]) kindsOf(name string) (kinds []string) {
+	if _, ok := p.aliases[name]; ok {
+		kinds = append(kinds, "alias")
+	}
+	if _, ok := p.groups[name]; ok {
+		kinds = append(kinds, "group")
+	}
+	if _, ok := p.commands[name]; ok {
+		kinds = append(kinds, "command")
+	}
+	return
+}
+
+// flagClash checks if flag has a long or short name in common with any of flags.
+// If so, returns the name (including leading dashes) and true.
+func flagClash(flag meta.Flag, flags []meta.Flag) (string, bool) {
+	for _, other := range flags {
+		for _, long := range flag.Long {
+			if slices.Contains(other.Long, long) {
+				return "--" + long, true
+			}
+		}
+		for _, short := range flag.Short {
+			if slices.Contains(other.Short, short) {
+				return "-" + short, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,164 @@
+//spellchecker:words goprogram
+package goprogram //nolint:testpackage // tests internal behavior
+
+//spellchecker:words errors testing github goprogram meta
+import (
+	"errors"
+	"testing"
+
+	"go.tkw01536.de/goprogram/meta"
+)
+
+func TestProgram_Validate(t *testing.T) {
+	t.Parallel()
+
+	type shadowCommand struct {
+		tCommand[struct{}]
+
+		GlobalOne string `long:"global-one"`
+		Other     string `long:"other"      short:"b"`
+	}
+	type rangeStruct = struct {
+		Args []string `required:"3-1"`
+	}
+
+	tests := []struct {
+		name  string
+		setup func(p *iProgram)
+
+		wantErrs []error
+		wantMsg  string
+	}{
+		{
+			name: "valid program",
+			setup: func(p *iProgram) {
+				p.RegisterGroup(Group{Name: "repo"})
+				p.Register(makeEchoCommand("repo echo"))
+				p.RegisterAlias(Alias{Name: "echo", Command: "echo"})
+				p.RegisterAlias(Alias{Name: "say", Command: "echo", Args: []string{"hello"}})
+				p.RegisterAlias(Alias{Name: "list", Command: "repo echo", Args: []string{"list"}})
+				p.RegisterKeyword("kw", func(args *iArguments, pos *[]string) error { return nil })
+			},
+		},
+		{
+			name: "keyword clashes",
+			setup: func(p *iProgram) {
+				p.RegisterKeyword("echo", func(args *iArguments, pos *[]string) error { return nil })
+				p.RegisterAlias(Alias{Name: "echo", Command: "echo"})
+			},
+			wantErrs: []error{errValidateClash},
+			wantMsg:  "name clash: keyword \"echo\" hides alias of the same name\nname clash: keyword \"echo\" hides command of the same name",
+		},
+		{
+			name: "alias to unknown child of group",
+			setup: func(p *iProgram) {
+				p.RegisterGroup(Group{Name: "repo"})
+				p.Register(makeEchoCommand("repo echo"))
+				p.RegisterAlias(Alias{Name: "x", Command: "repo", Args: []string{"nope"}})
+				p.RegisterAlias(Alias{Name: "y", Command: "repo", Args: []string{"echo", "nope"}})
+			},
+			wantErrs: []error{errValidateAlias},
+			wantMsg:  "invalid alias \"x\": expands into unknown command \"repo nope\"",
+		},
+		{
+			name: "alias clashes",
+			setup: func(p *iProgram) {
+				p.RegisterGroup(Group{Name: "repo"})
+				p.Register(makeEchoCommand("repo echo"))
+				p.RegisterAlias(Alias{Name: "echo", Command: "repo echo"})
+				p.RegisterAlias(Alias{Name: "repo", Command: "echo"})
+			},
+			wantErrs: []error{errValidateClash},
+			wantMsg:  "name clash: alias \"echo\" hides command of the same name\nname clash: alias \"repo\" hides group of the same name",
+		},
+		{
+			name: "alias to unknown command",
+			setup: func(p *iProgram) {
+				p.RegisterAlias(Alias{Name: "a", Command: "b"})
+				p.RegisterAlias(Alias{Name: "b", Command: "missing"})
+				p.RegisterAlias(Alias{Name: "c", Command: "d"})
+				p.RegisterAlias(Alias{Name: "d", Command: "c"})
+			},
+			wantErrs: []error{errValidateAlias, errAliasCycle},
+			wantMsg:  "invalid alias \"a\": expands into unknown command \"missing\"\ninvalid alias \"b\": expands into unknown command \"missing\"\ninvalid alias \"c\": alias expansion failed: aliases form a cycle: \"c\" -> \"d\" -> \"c\"\ninvalid alias \"d\": alias expansion failed: aliases form a cycle: \"d\" -> \"c\" -> \"d\"",
+		},
+		{
+			name: "command flags shadowing global flags",
+			setup: func(p *iProgram) {
+				p.Register(&shadowCommand{
+					tCommand: tCommand[struct{}]{
+						MDesc: iDescription{Command: "shadow", Requirements: func(flag meta.Flag) bool { return true }},
+					},
+				})
+			},
+			wantErrs: []error{errValidateShadow},
+			wantMsg:  "flag shadows global flag: command \"shadow\": \"--global-one\"\nflag shadows global flag: command \"shadow\": \"-b\"",
+		},
+		{
+			name: "invalid positional range",
+			setup: func(p *iProgram) {
+				p.Register(&tCommand[rangeStruct]{
+					MDesc: iDescription{Command: "range", Requirements: func(flag meta.Flag) bool { return true }},
+				})
+			},
+			wantErrs: []error{errValidatePositional},
+			wantMsg:  "invalid range of positional arguments: command \"range\": \"Args\" takes between 3 and 1 arguments",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			program := makeProgram()
+			program.Register(makeEchoCommand("echo"))
+			tt.setup(&program)
+
+			err := program.Validate()
+			for _, want := range tt.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("Program.Validate() = %v, want error wrapping %v", err, want)
+				}
+			}
+
+			gotMsg := ""
+			if err != nil {
+				gotMsg = err.Error()
+			}
+			if gotMsg != tt.wantMsg {
+				t.Errorf("Program.Validate() message = %q, want %q", gotMsg, tt.wantMsg)
+			}
+		})
+	}
+}
+
+// tReferencingRequirements are requirements referring to specific global flags.
+type tReferencingRequirements []string
+
+func (tReferencingRequirements) AllowsFlag(flag meta.Flag) bool   { return true }
+func (tReferencingRequirements) Validate(Arguments[tFlags]) error { return nil }
+func (t tReferencingRequirements) ReferencedFlags() []string      { return t }
+
+// tReferencingCommand is a command using tReferencingRequirements.
+type tReferencingCommand tReferencingRequirements
+
+func (tReferencingCommand) Run(Context[tEnvironment, tParameters, tFlags, tReferencingRequirements]) error {
+	return nil
+}
+func (t tReferencingCommand) Description() Description[tFlags, tReferencingRequirements] {
+	return Description[tFlags, tReferencingRequirements]{Command: "ref", Requirements: tReferencingRequirements(t)}
+}
+
+func TestProgram_Validate_requirements(t *testing.T) {
+	t.Parallel()
+
+	var program Program[tEnvironment, tParameters, tFlags, tReferencingRequirements]
+	program.Register(tReferencingCommand{"GlobalOne", "Global1"})
+
+	err := program.Validate()
+	if !errors.Is(err, errValidateRequirement) {
+		t.Errorf("Program.Validate() = %v, want error wrapping %v", err, errValidateRequirement)
+	}
+	if want := "requirement refers to unknown global flag: command \"ref\": \"Global1\""; err == nil || err.Error() != want {
+		t.Errorf("Program.Validate() = %v, want %q", err, want)
+	}
+}
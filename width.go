@@ -0,0 +1,42 @@
+//spellchecker:words goprogram
+package goprogram
+
+//spellchecker:words strconv github goprogram meta terminal pkglib stream
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"go.tkw01536.de/goprogram/meta"
+	"go.tkw01536.de/goprogram/terminal"
+	"go.tkw01536.de/pkglib/stream"
+)
+
+// printUsage prints the given usage page to the standard output of context.
+// The page is wrapped to the width of the output, and styled according to the "--color" flag.
+func (p Program[E, P, F, R]) printUsage(context Context[E, P, F, R], page meta.Meta) error {
+	page.Width = p.usageWidth(context.IOStream)
+	page.Styled = context.Args.Universals.Color.Enabled(context.Stdout)
+	if _, err := context.Println(page.String()); err != nil {
+		return fmt.Errorf("%w: %w", errProgramIO, err)
+	}
+	return nil
+}
+
+// usageWidth returns the width to wrap usage pages written to str to, see UsageWidth.
+// A return value of zero or less indicates that usage pages should not be wrapped.
+func (p Program[E, P, F, R]) usageWidth(str stream.IOStream) int {
+	if p.UsageWidth != 0 {
+		return p.UsageWidth
+	}
+
+	// COLUMNS is only honoured for terminals, so that piped or redirected output is never wrapped.
+	columns, ok := terminal.Width(str.Stdout)
+	if !ok {
+		return 0
+	}
+	if value, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && value > 0 {
+		return value
+	}
+	return columns
+}
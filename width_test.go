@@ -0,0 +1,84 @@
+//spellchecker:words goprogram
+package goprogram //nolint:testpackage // tests internal behavior
+
+//spellchecker:words bytes strings testing github pkglib stream
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"go.tkw01536.de/pkglib/stream"
+)
+
+//nolint:paralleltest // uses t.Setenv
+func TestProgram_UsageWidth(t *testing.T) {
+	tests := []struct {
+		name       string
+		usageWidth int
+		columns    string
+		wantFirst  string
+	}{
+		{"output is not a terminal", 0, "", "Usage: exe [--help|-h] [--version|-v] [--timeout duration] [--color when] [--global-one|-a] [--global-two|-b] [--] COMMAND [ARGS...]"},
+		{"columns without a terminal", 0, "60", "Usage: exe [--help|-h] [--version|-v] [--timeout duration] [--color when] [--global-one|-a] [--global-two|-b] [--] COMMAND [ARGS...]"},
+		{"invalid columns", 0, "wide", "Usage: exe [--help|-h] [--version|-v] [--timeout duration] [--color when] [--global-one|-a] [--global-two|-b] [--] COMMAND [ARGS...]"},
+		{"wrapping disabled", -1, "60", "Usage: exe [--help|-h] [--version|-v] [--timeout duration] [--color when] [--global-one|-a] [--global-two|-b] [--] COMMAND [ARGS...]"},
+		{"explicit width", 50, "60", "Usage: exe [--help|-h] [--version|-v]\n       [--timeout duration] [--color when]\n       [--global-one|-a] [--global-two|-b] [--]\n       COMMAND [ARGS...]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("COLUMNS", tt.columns)
+
+			var stdoutBuffer bytes.Buffer
+			str := stream.NewIOStream(&stdoutBuffer, nil, nil)
+
+			program := makeProgram()
+			program.UsageWidth = tt.usageWidth
+			program.Register(makeEchoCommand("echo"))
+
+			if err := program.Main(str, "", []string{"--help"}); err != nil {
+				t.Fatalf("Program.Main() error = %v", err)
+			}
+
+			gotFirst, _, _ := strings.Cut(stdoutBuffer.String(), "\n\n")
+			if gotFirst != tt.wantFirst {
+				t.Errorf("Program.Main() usage line = %q, want %q", gotFirst, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestProgram_Main_color(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantStdout string
+		wantStderr string
+	}{
+		{"plain help", []string{"--color=never", "--help"}, "Usage: exe", ""},
+		{"styled help", []string{"--color=always", "--help"}, "\x1b[1mUsage:\x1b[0m exe", ""},
+		{"plain error", []string{"--color=never", "missing"}, "", "unknown command: "},
+		{"styled error", []string{"--color=always", "missing"}, "", "\x1b[1;31munknown command\x1b[0m: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var stdoutBuffer, stderrBuffer bytes.Buffer
+			str := stream.NewIOStream(&stdoutBuffer, &stderrBuffer, nil)
+
+			program := makeProgram()
+			program.Register(makeEchoCommand("echo"))
+
+			_ = program.Main(str, "", tt.args)
+
+			if gotStdout := stdoutBuffer.String(); !strings.HasPrefix(gotStdout, tt.wantStdout) || (tt.wantStdout == "" && gotStdout != "") {
+				t.Errorf("Program.Main() stdout = %q, want prefix %q", gotStdout, tt.wantStdout)
+			}
+			if gotStderr := stderrBuffer.String(); !strings.HasPrefix(gotStderr, tt.wantStderr) || (tt.wantStderr == "" && gotStderr != "") {
+				t.Errorf("Program.Main() stderr = %q, want prefix %q", gotStderr, tt.wantStderr)
+			}
+		})
+	}
+}